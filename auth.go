@@ -0,0 +1,59 @@
+package delegate
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+)
+
+// NonceSize is the size of the AuthChallenge nonce.
+const NonceSize = 32
+
+// MaxAuthKeyIDSize is the maximum size of the AuthResponse key ID.
+const MaxAuthKeyIDSize = 255
+
+// authLabel separates handshake MACs from any other use of the same key.
+const authLabel = "cmd-stream auth v1"
+
+// AuthKey is a shared secret identified by ID. Several keys with different
+// IDs allow the secret to be rotated without downtime.
+type AuthKey struct {
+	ID     string
+	Secret []byte
+}
+
+// AuthChallenge is sent by the server to the client during the handshake.
+type AuthChallenge struct {
+	Nonce []byte
+}
+
+// NewAuthChallenge creates a new AuthChallenge with a random nonce.
+func NewAuthChallenge() (c AuthChallenge, err error) {
+	c.Nonce = make([]byte, NonceSize)
+	_, err = rand.Read(c.Nonce)
+	return
+}
+
+// AuthResponse is sent by the client in reply to AuthChallenge.
+type AuthResponse struct {
+	KeyID string
+	MAC   []byte
+}
+
+// NewAuthResponse creates an AuthResponse to the challenge signed with the
+// key.
+func NewAuthResponse(key AuthKey, c AuthChallenge) AuthResponse {
+	return AuthResponse{KeyID: key.ID, MAC: authMAC(key.Secret, c.Nonce)}
+}
+
+// Verify checks that the response to the challenge was signed with the key.
+func (r AuthResponse) Verify(key AuthKey, c AuthChallenge) bool {
+	return r.KeyID == key.ID && hmac.Equal(r.MAC, authMAC(key.Secret, c.Nonce))
+}
+
+func authMAC(secret, nonce []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(authLabel))
+	mac.Write(nonce)
+	return mac.Sum(nil)
+}
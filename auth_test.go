@@ -0,0 +1,44 @@
+package delegate
+
+import (
+	"testing"
+
+	asserterror "github.com/ymz-ncnk/assert/error"
+)
+
+func TestAuth(t *testing.T) {
+	var (
+		key      = AuthKey{ID: "1", Secret: []byte("secret")}
+		otherKey = AuthKey{ID: "1", Secret: []byte("other secret")}
+	)
+
+	t.Run("NewAuthChallenge should generate a random nonce", func(t *testing.T) {
+		c1, err := NewAuthChallenge()
+		asserterror.EqualError(t, err, nil)
+		c2, err := NewAuthChallenge()
+		asserterror.EqualError(t, err, nil)
+		asserterror.Equal(t, len(c1.Nonce), NonceSize)
+		asserterror.Equal(t, string(c1.Nonce) != string(c2.Nonce), true)
+	})
+
+	t.Run("AuthResponse should be verified with the same key", func(t *testing.T) {
+		c, _ := NewAuthChallenge()
+		resp := NewAuthResponse(key, c)
+		asserterror.Equal(t, resp.KeyID, key.ID)
+		asserterror.Equal(t, resp.Verify(key, c), true)
+	})
+
+	t.Run("AuthResponse should not be verified with another key, key ID or challenge",
+		func(t *testing.T) {
+			var (
+				c, _      = NewAuthChallenge()
+				another   = AuthChallenge{Nonce: append([]byte{}, c.Nonce...)}
+				resp      = NewAuthResponse(key, c)
+				anotherID = AuthKey{ID: "2", Secret: key.Secret}
+			)
+			another.Nonce[0]++
+			asserterror.Equal(t, resp.Verify(otherKey, c), false)
+			asserterror.Equal(t, resp.Verify(anotherID, c), false)
+			asserterror.Equal(t, resp.Verify(key, another), false)
+		})
+}
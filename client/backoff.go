@@ -0,0 +1,90 @@
+package client
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff determines how long ReconnectDelegate waits between failed
+// reconnect attempts.
+type Backoff interface {
+	// Next returns the delay before the next attempt. The attempt parameter is
+	// the number of failed attempts so far (starting from 1), prev is the
+	// delay returned by the previous call (0 on the first call).
+	Next(attempt int, prev time.Duration) time.Duration
+}
+
+// NewConstantBackoff creates a new ConstantBackoff.
+func NewConstantBackoff(delay time.Duration) ConstantBackoff {
+	return ConstantBackoff{delay: delay}
+}
+
+// ConstantBackoff waits the same amount of time before each attempt.
+type ConstantBackoff struct {
+	delay time.Duration
+}
+
+func (b ConstantBackoff) Next(attempt int, prev time.Duration) time.Duration {
+	return b.delay
+}
+
+// NewExponentialBackoff creates a new ExponentialBackoff.
+//
+// The delay starts at base and doubles after each failed attempt, but never
+// exceeds max. If max == 0, the delay is not limited.
+func NewExponentialBackoff(base, max time.Duration) ExponentialBackoff {
+	return ExponentialBackoff{base: base, max: max}
+}
+
+// ExponentialBackoff doubles the delay after each failed attempt.
+type ExponentialBackoff struct {
+	base time.Duration
+	max  time.Duration
+}
+
+func (b ExponentialBackoff) Next(attempt int, prev time.Duration) (
+	delay time.Duration,
+) {
+	delay = b.base
+	for i := 1; i < attempt; i++ {
+		if b.max != 0 && delay >= b.max/2 {
+			return b.max
+		}
+		delay *= 2
+	}
+	if b.max != 0 && delay > b.max {
+		delay = b.max
+	}
+	return
+}
+
+// NewDecorrelatedJitterBackoff creates a new DecorrelatedJitterBackoff.
+//
+// Each delay is a random value between base and three times the previous
+// delay, limited by max. If max == 0, the delay is not limited.
+func NewDecorrelatedJitterBackoff(base, max time.Duration) DecorrelatedJitterBackoff {
+	return DecorrelatedJitterBackoff{base: base, max: max}
+}
+
+// DecorrelatedJitterBackoff randomizes delays so that many clients
+// reconnecting at the same time do not hit the server simultaneously.
+type DecorrelatedJitterBackoff struct {
+	base time.Duration
+	max  time.Duration
+}
+
+func (b DecorrelatedJitterBackoff) Next(attempt int, prev time.Duration) (
+	delay time.Duration,
+) {
+	if prev < b.base {
+		prev = b.base
+	}
+	delay = b.base
+	if upper := prev * 3; upper > b.base {
+		delay += rand.N(upper - b.base)
+	}
+	if b.max != 0 && delay > b.max {
+		delay = b.max
+	}
+	return
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	asserterror "github.com/ymz-ncnk/assert/error"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Run("ConstantBackoff should always return the same delay",
+		func(t *testing.T) {
+			var (
+				wantDelay = 100 * time.Millisecond
+				b         = NewConstantBackoff(wantDelay)
+			)
+			for attempt := 1; attempt < 5; attempt++ {
+				asserterror.Equal(t, b.Next(attempt, 0), wantDelay)
+			}
+		})
+
+	t.Run("ExponentialBackoff should double the delay up to max",
+		func(t *testing.T) {
+			var (
+				b          = NewExponentialBackoff(time.Second, 5*time.Second)
+				wantDelays = []time.Duration{
+					time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second,
+					5 * time.Second,
+				}
+			)
+			for i, wantDelay := range wantDelays {
+				asserterror.Equal(t, b.Next(i+1, 0), wantDelay)
+			}
+		})
+
+	t.Run("ExponentialBackoff without max should not be limited",
+		func(t *testing.T) {
+			b := NewExponentialBackoff(time.Millisecond, 0)
+			asserterror.Equal(t, b.Next(11, 0), 1024*time.Millisecond)
+		})
+
+	t.Run("DecorrelatedJitterBackoff should return a delay between base and 3*prev, limited by max",
+		func(t *testing.T) {
+			var (
+				base  = 10 * time.Millisecond
+				max   = time.Second
+				b     = NewDecorrelatedJitterBackoff(base, max)
+				delay time.Duration
+			)
+			for attempt := 1; attempt < 100; attempt++ {
+				prev := delay
+				delay = b.Next(attempt, prev)
+				if delay < base || delay > max {
+					t.Fatalf("unexpected delay %v", delay)
+				}
+				if prev >= base && delay > 3*prev {
+					t.Fatalf("delay %v is bigger than 3*%v", delay, prev)
+				}
+			}
+		})
+}
@@ -12,7 +12,8 @@
 //     when the connection to the server is lost.
 //
 // All delegates rely on a pluggable Transport for data exchange and support
-// configurable options such as send/receive deadlines.
+// configurable options such as send/receive deadlines. FailoverFactory allows
+// ReconnectDelegate to switch between several server endpoints.
 //
 // Deprecated: migrate to github.com/cmd-stream/cmd-stream-go instead.
 package client
@@ -1,7 +1,6 @@
 package client
 
 import (
-	"bytes"
 	"net"
 	"time"
 
@@ -11,15 +10,22 @@ import (
 
 // New creates a new Delegate.
 //
-// The Delegate expects to receive ServerInfo from the server upon creation.
+// The Delegate expects to receive ServerInfo from the server upon creation,
+// preceded by delegate.Preamble if the WithPreamble option is used. With the
+// WithAdmission option, returns RejectedError if the server rejects the
+// connection, or RedirectError if it redirects the client to another
+// address.
 //
-// Returns ErrServerInfoMismatch if the received ServerInfo does not match
-// the specified one.
+// Returns an error wrapping ErrServerInfoMismatch if the received ServerInfo
+// does not match the specified one. By default ServerInfo must match exactly,
+// this can be changed with the WithServerInfoMatcher option. If AuthKey is
+// configured, the client then answers the server's authentication challenge.
+// If ClientInfo is configured, it is sent to the server last.
 func New[T any](info delegate.ServerInfo, transport Transport[T],
 	opts ...SetOption,
 ) (d Delegate[T], err error) {
 	Apply(opts, &d.options)
-	err = checkServerInfo(d.options.ServerInfoReceiveDuration, transport, info)
+	err = handshake(transport, info, d.options)
 	if err != nil {
 		return
 	}
@@ -77,22 +83,102 @@ func (d Delegate[T]) Close() error {
 	return d.transport.Close()
 }
 
-func checkServerInfo[T any](timeout time.Duration,
-	transport Transport[T],
+func handshake[T any](transport Transport[T], wantInfo delegate.ServerInfo,
+	options Options,
+) (err error) {
+	err = transport.SetReceiveDeadline(calcDeadline(
+		options.ServerInfoReceiveDuration))
+	if err != nil {
+		return
+	}
+	if options.Preamble {
+		if err = checkPreamble(transport); err != nil {
+			return
+		}
+	}
+	if options.Admission {
+		if err = checkAdmission(transport); err != nil {
+			return
+		}
+	}
+	if err = checkServerInfo(transport, wantInfo, options); err != nil {
+		return
+	}
+	if len(options.AuthKey.Secret) != 0 {
+		if err = authenticate(transport, options.AuthKey); err != nil {
+			return
+		}
+	}
+	if err = transport.SetReceiveDeadline(time.Time{}); err != nil {
+		return
+	}
+	if options.ClientInfo != nil {
+		err = transport.SendClientInfo(options.ClientInfo)
+	}
+	return
+}
+
+func checkPreamble[T any](transport Transport[T]) (err error) {
+	p, err := transport.ReceivePreamble()
+	if err != nil {
+		return
+	}
+	if p.Magic != delegate.Magic {
+		return ErrNotCmdStreamPeer
+	}
+	if p.Version != delegate.HandshakeVersion {
+		return ErrUnsupportedHandshakeVersion
+	}
+	return
+}
+
+func checkAdmission[T any](transport Transport[T]) (err error) {
+	reason, err := transport.ReceiveAdmission()
+	if err != nil {
+		return
+	}
+	switch reason {
+	case delegate.RejectNone:
+		return
+	case delegate.RejectRedirect:
+		var redirect delegate.Redirect
+		if redirect, err = transport.ReceiveRedirect(); err != nil {
+			return
+		}
+		return NewRedirectError(redirect.Addr)
+	default:
+		return NewRejectedError(reason)
+	}
+}
+
+func checkServerInfo[T any](transport Transport[T],
 	wantInfo delegate.ServerInfo,
+	options Options,
 ) (err error) {
-	err = transport.SetReceiveDeadline(calcDeadline(timeout))
+	info, err := transport.ReceiveServerInfo(options.MaxServerInfoSize)
 	if err != nil {
 		return
 	}
-	info, err := transport.ReceiveServerInfo()
+	return matchServerInfo(wantInfo, info, options)
+}
+
+func authenticate[T any](transport Transport[T], key delegate.AuthKey) (
+	err error,
+) {
+	challenge, err := transport.ReceiveAuthChallenge()
 	if err != nil {
 		return
 	}
-	if !bytes.Equal(info, wantInfo) {
-		return ErrServerInfoMismatch
+	return transport.SendAuthResponse(delegate.NewAuthResponse(key, challenge))
+}
+
+func matchServerInfo(wantInfo, info delegate.ServerInfo,
+	options Options,
+) error {
+	if options.ServerInfoMatcher == nil {
+		return ExactMatcher{}.Match(wantInfo, info)
 	}
-	return transport.SetReceiveDeadline(time.Time{})
+	return options.ServerInfoMatcher.Match(wantInfo, info)
 }
 
 func calcDeadline(duration time.Duration) (deadline time.Time) {
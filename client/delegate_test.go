@@ -2,6 +2,7 @@ package client_test
 
 import (
 	"errors"
+	"fmt"
 	"net"
 	"testing"
 	"time"
@@ -59,7 +60,7 @@ func TestDelegate(t *testing.T) {
 						return nil
 					},
 				).RegisterReceiveServerInfo(
-					func() (info delegate.ServerInfo, err error) {
+					func(maxSize int) (info delegate.ServerInfo, err error) {
 						return nil, wantErr
 					},
 				)
@@ -80,7 +81,7 @@ func TestDelegate(t *testing.T) {
 						return nil
 					},
 				).RegisterReceiveServerInfo(
-					func() (info delegate.ServerInfo, err error) {
+					func(maxSize int) (info delegate.ServerInfo, err error) {
 						return wrongServerInfo, nil
 					},
 				)
@@ -103,7 +104,7 @@ func TestDelegate(t *testing.T) {
 					return
 				},
 			).RegisterReceiveServerInfo(
-				func() (info delegate.ServerInfo, err error) {
+				func(maxSize int) (info delegate.ServerInfo, err error) {
 					return serverInfo, nil
 				},
 			).RegisterSetReceiveDeadline(
@@ -188,7 +189,7 @@ func TestDelegate(t *testing.T) {
 				delegate = dcln.Delegate[any]{}
 			)
 			o := delegate.Options()
-			asserterror.Equal(t, o, wantO)
+			asserterror.EqualDeep(t, o, wantO)
 		})
 
 	t.Run("LocalAddr should return Transport.LocalAddr", func(t *testing.T) {
@@ -237,6 +238,258 @@ func TestDelegate(t *testing.T) {
 			err := delegate.Close()
 			asserterror.EqualError(t, err, wantErr)
 		})
+
+	t.Run("With ServerVersionRange, New should accept a compatible server",
+		func(t *testing.T) {
+			var (
+				wantInfo = delegate.VersionedInfo{
+					Service:  "service",
+					Version:  delegate.Version{Major: 1},
+					Features: []string{"feature"},
+				}
+				serverInfo = delegate.VersionedInfo{
+					Service:  "service",
+					Version:  delegate.Version{Major: 1, Minor: 3},
+					Features: []string{"feature", "new feature"},
+				}
+				transport = makeClientTransport(serverInfo.ServerInfo())
+				mocks     = []*mok.Mock{transport.Mock}
+				ops       = []dcln.SetOption{
+					dcln.WithServerVersionRange(delegate.VersionRange{
+						Min: delegate.Version{Major: 1},
+						Max: delegate.Version{Major: 2},
+					}),
+				}
+			)
+			_, err := dcln.New(wantInfo.ServerInfo(), transport, ops...)
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("With ServerVersionRange, New should reject an incompatible server",
+		func(t *testing.T) {
+			var (
+				wantErr  = dcln.ErrServerInfoMismatch
+				wantInfo = delegate.VersionedInfo{
+					Service:  "service",
+					Version:  delegate.Version{Major: 1},
+					Features: []string{"feature"},
+				}
+				ops = []dcln.SetOption{
+					dcln.WithServerVersionRange(delegate.VersionRange{
+						Min: delegate.Version{Major: 1},
+						Max: delegate.Version{Major: 2},
+					}),
+				}
+			)
+			for _, info := range []delegate.ServerInfo{
+				delegate.VersionedInfo{
+					Service:  "another service",
+					Version:  delegate.Version{Major: 1},
+					Features: []string{"feature"},
+				}.ServerInfo(),
+				delegate.VersionedInfo{
+					Service:  "service",
+					Version:  delegate.Version{Major: 2},
+					Features: []string{"feature"},
+				}.ServerInfo(),
+				delegate.VersionedInfo{
+					Service: "service",
+					Version: delegate.Version{Major: 1},
+				}.ServerInfo(),
+				[]byte{255},
+			} {
+				var (
+					transport = clnmock.NewTransport().RegisterSetReceiveDeadline(
+						func(deadline time.Time) (err error) { return nil },
+					).RegisterReceiveServerInfo(
+						func(maxSize int) (i delegate.ServerInfo, err error) { return info, nil },
+					)
+					mocks = []*mok.Mock{transport.Mock}
+				)
+				_, err := dcln.New(wantInfo.ServerInfo(), transport, ops...)
+				if !errors.Is(err, wantErr) {
+					t.Errorf("unexpected error %v", err)
+				}
+				asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+			}
+		})
+
+	t.Run("New should check ServerInfo with ServerInfoMatcher",
+		func(t *testing.T) {
+			var (
+				wantErr = fmt.Errorf("%w: custom", dcln.ErrServerInfoMismatch)
+				gotInfo = delegate.ServerInfo("another server info")
+				matcher = clnmock.NewServerInfoMatcher().RegisterMatch(
+					func(want, got delegate.ServerInfo) (err error) {
+						asserterror.EqualDeep(t, want, serverInfo)
+						asserterror.EqualDeep(t, got, gotInfo)
+						return wantErr
+					},
+				)
+				transport = clnmock.NewTransport().RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterReceiveServerInfo(
+					func(maxSize int) (i delegate.ServerInfo, err error) { return gotInfo, nil },
+				)
+				mocks = []*mok.Mock{transport.Mock, matcher.Mock}
+			)
+			_, err := dcln.New(serverInfo, transport,
+				dcln.WithServerInfoMatcher(matcher))
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If ClientInfo is set, New should send it after ServerInfo",
+		func(t *testing.T) {
+			var (
+				wantInfo  = delegate.ClientInfo("client info")
+				transport = makeClientTransport(serverInfo).RegisterSendClientInfo(
+					func(info delegate.ClientInfo) (err error) {
+						asserterror.EqualDeep(t, info, wantInfo)
+						return nil
+					},
+				)
+				mocks = []*mok.Mock{transport.Mock}
+			)
+			_, err := dcln.New(serverInfo, transport, dcln.WithClientInfo(wantInfo))
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If Transport.SendClientInfo fails with an error, New should return it",
+		func(t *testing.T) {
+			var (
+				wantErr   = errors.New("SendClientInfo error")
+				transport = makeClientTransport(serverInfo).RegisterSendClientInfo(
+					func(info delegate.ClientInfo) (err error) { return wantErr },
+				)
+				mocks = []*mok.Mock{transport.Mock}
+			)
+			_, err := dcln.New(serverInfo, transport,
+				dcln.WithClientInfo(delegate.ClientInfo("client info")))
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If AuthKey is set, New should answer the authentication challenge",
+		func(t *testing.T) {
+			var (
+				key          = delegate.AuthKey{ID: "1", Secret: []byte("secret")}
+				challenge, _ = delegate.NewAuthChallenge()
+				transport    = clnmock.NewTransport().RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterReceiveServerInfo(
+					func(maxSize int) (i delegate.ServerInfo, err error) { return serverInfo, nil },
+				).RegisterReceiveAuthChallenge(
+					func() (c delegate.AuthChallenge, err error) { return challenge, nil },
+				).RegisterSendAuthResponse(
+					func(r delegate.AuthResponse) (err error) {
+						asserterror.Equal(t, r.Verify(key, challenge), true)
+						return nil
+					},
+				).RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				)
+				mocks = []*mok.Mock{transport.Mock}
+			)
+			_, err := dcln.New(serverInfo, transport, dcln.WithAuthKey(key))
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If Transport.ReceiveAuthChallenge fails with an error, New should return it",
+		func(t *testing.T) {
+			var (
+				wantErr   = errors.New("ReceiveAuthChallenge error")
+				transport = clnmock.NewTransport().RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterReceiveServerInfo(
+					func(maxSize int) (i delegate.ServerInfo, err error) { return serverInfo, nil },
+				).RegisterReceiveAuthChallenge(
+					func() (c delegate.AuthChallenge, err error) {
+						return delegate.AuthChallenge{}, wantErr
+					},
+				)
+				mocks = []*mok.Mock{transport.Mock}
+			)
+			_, err := dcln.New(serverInfo, transport,
+				dcln.WithAuthKey(delegate.AuthKey{Secret: []byte("secret")}))
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("MaxServerInfoSize should be passed to Transport.ReceiveServerInfo",
+		func(t *testing.T) {
+			var (
+				wantErr     = delegate.ErrServerInfoTooLarge
+				wantMaxSize = len(serverInfo) - 1
+				transport   = clnmock.NewTransport().RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterReceiveServerInfo(
+					func(maxSize int) (i delegate.ServerInfo, err error) {
+						asserterror.Equal(t, maxSize, wantMaxSize)
+						return nil, wantErr
+					},
+				)
+				mocks = []*mok.Mock{transport.Mock}
+			)
+			_, err := dcln.New(serverInfo, transport,
+				dcln.WithMaxServerInfoSize(wantMaxSize))
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If WithPreamble is used, New should check Preamble", func(t *testing.T) {
+		for _, c := range []struct {
+			preamble delegate.Preamble
+			wantErr  error
+		}{
+			{delegate.NewPreamble(), nil},
+			{delegate.Preamble{Magic: [4]byte{'H', 'T', 'T', 'P'}},
+				dcln.ErrNotCmdStreamPeer},
+			{delegate.Preamble{Magic: delegate.Magic,
+				Version: delegate.HandshakeVersion + 1},
+				dcln.ErrUnsupportedHandshakeVersion},
+		} {
+			var (
+				transport = clnmock.NewTransport().RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterReceivePreamble(
+					func() (p delegate.Preamble, err error) { return c.preamble, nil },
+				)
+				mocks = []*mok.Mock{transport.Mock}
+			)
+			if c.wantErr == nil {
+				transport.RegisterReceiveServerInfo(
+					func(maxSize int) (i delegate.ServerInfo, err error) { return serverInfo, nil },
+				).RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				)
+			}
+			_, err := dcln.New(serverInfo, transport, dcln.WithPreamble())
+			asserterror.EqualError(t, err, c.wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		}
+	})
+
+	t.Run("If the server rejects the connection, New should return RejectedError",
+		func(t *testing.T) {
+			var (
+				wantErr   = dcln.NewRejectedError(delegate.RejectForbidden)
+				transport = clnmock.NewTransport().RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterReceiveAdmission(
+					func() (reason delegate.RejectReason, err error) {
+						return delegate.RejectForbidden, nil
+					},
+				)
+				mocks = []*mok.Mock{transport.Mock}
+			)
+			_, err := dcln.New(serverInfo, transport, dcln.WithAdmission())
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
 }
 
 func makeClientTransport(serverInfo delegate.ServerInfo) clnmock.Transport {
@@ -245,7 +498,7 @@ func makeClientTransport(serverInfo delegate.ServerInfo) clnmock.Transport {
 			return nil
 		},
 	).RegisterReceiveServerInfo(
-		func() (i delegate.ServerInfo, err error) {
+		func(maxSize int) (i delegate.ServerInfo, err error) {
 			return serverInfo, nil
 		},
 	).RegisterSetReceiveDeadline(
@@ -1,7 +1,118 @@
 package client
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cmd-stream/delegate-go"
+)
+
+// ErrNotCmdStreamPeer happens when the Preamble received from the server
+// does not contain delegate.Magic, i.e. the client is connected to something
+// other than a cmd-stream server.
+var ErrNotCmdStreamPeer = errors.New("not a cmd-stream peer")
+
+// ErrUnsupportedHandshakeVersion happens when the server uses a handshake
+// version the client does not support.
+var ErrUnsupportedHandshakeVersion = errors.New("unsupported handshake version")
 
 // ErrServerInfoMismatch happens when ServerInfo of the client and server
 // does not match.
 var ErrServerInfoMismatch = errors.New("server info mismatch")
+
+// ErrNoFactories happens when FailoverFactory is created without
+// TransportFactories.
+var ErrNoFactories = errors.New("no transport factories")
+
+// ErrRedirectNotSupported happens when FailoverFactory is asked to follow a
+// redirect, but the factory of the current endpoint is not a
+// RedirectTransportFactory.
+var ErrRedirectNotSupported = errors.New("redirect not supported")
+
+// ErrKeepaliveTimeout happens when the server does not respond to Ping
+// Commands. It implements net.Error, so the client treats it as a connection
+// loss.
+var ErrKeepaliveTimeout error = keepaliveTimeoutError{}
+
+// ErrMaxReconnectAttempts happens when ReconnectDelegate has made the maximum
+// number of reconnect attempts.
+var ErrMaxReconnectAttempts = errors.New("max reconnect attempts reached")
+
+// NewReconnectError creates a new ReconnectError.
+func NewReconnectError(attempts int, cause, lastErr error) ReconnectError {
+	return ReconnectError{Attempts: attempts, Cause: cause, LastErr: lastErr}
+}
+
+// ReconnectError is returned by ReconnectDelegate when it gives up
+// reconnecting.
+//
+// Cause describes why reconnecting was stopped (ErrMaxReconnectAttempts or a
+// context error), LastErr holds the error of the last failed dial or
+// handshake attempt, if any.
+type ReconnectError struct {
+	Attempts int
+	Cause    error
+	LastErr  error
+}
+
+func (e ReconnectError) Error() string {
+	if e.LastErr == nil {
+		return fmt.Sprintf("reconnect failed after %d attempts: %v", e.Attempts,
+			e.Cause)
+	}
+	return fmt.Sprintf("reconnect failed after %d attempts: %v, last error: %v",
+		e.Attempts, e.Cause, e.LastErr)
+}
+
+func (e ReconnectError) Unwrap() []error {
+	return []error{e.Cause, e.LastErr}
+}
+
+// ErrTooManyRedirects happens when ReconnectDelegate is redirected more than
+// MaxRedirects times in a row.
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// NewRedirectError creates a new RedirectError.
+func NewRedirectError(addr string) RedirectError {
+	return RedirectError{Addr: addr}
+}
+
+// RedirectError happens when the server redirects the client to another
+// address during the handshake.
+type RedirectError struct {
+	Addr string
+}
+
+func (e RedirectError) Error() string {
+	return fmt.Sprintf("redirected by the server to %v", e.Addr)
+}
+
+// NewRejectedError creates a new RejectedError.
+func NewRejectedError(reason delegate.RejectReason) RejectedError {
+	return RejectedError{Reason: reason}
+}
+
+// RejectedError happens when the server rejects the connection during the
+// handshake.
+type RejectedError struct {
+	Reason delegate.RejectReason
+}
+
+func (e RejectedError) Error() string {
+	return fmt.Sprintf("rejected by the server: %v", e.Reason)
+}
+
+// Retryable reports whether a later attempt to connect may succeed. Only
+// delegate.RejectBusy and delegate.RejectShuttingDown are retryable.
+func (e RejectedError) Retryable() bool {
+	return e.Reason == delegate.RejectBusy ||
+		e.Reason == delegate.RejectShuttingDown
+}
+
+type keepaliveTimeoutError struct{}
+
+func (e keepaliveTimeoutError) Error() string { return "keepalive timeout" }
+
+func (e keepaliveTimeoutError) Timeout() bool { return true }
+
+func (e keepaliveTimeoutError) Temporary() bool { return false }
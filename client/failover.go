@@ -0,0 +1,145 @@
+package client
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// FailoverPolicy defines the order in which FailoverFactory tries endpoints.
+type FailoverPolicy int
+
+const (
+	// FailoverOrdered always tries endpoints starting from the first one.
+	FailoverOrdered FailoverPolicy = iota
+	// FailoverRoundRobin starts each call from the endpoint following the one
+	// used by the previous call.
+	FailoverRoundRobin
+	// FailoverRandom tries endpoints in random order.
+	FailoverRandom
+	// FailoverSticky keeps using the last successful endpoint until it fails.
+	FailoverSticky
+)
+
+// NewFailoverFactory creates a new FailoverFactory.
+//
+// Panics with ErrNoFactories if factories is empty.
+func NewFailoverFactory[T any](factories []TransportFactory[T],
+	ops ...SetFailoverOption,
+) *FailoverFactory[T] {
+	if len(factories) == 0 {
+		panic(ErrNoFactories)
+	}
+	f := &FailoverFactory[T]{
+		factories: factories,
+		downUntil: make([]time.Time, len(factories)),
+		last:      -1,
+	}
+	ApplyFailover(ops, &f.options)
+	return f
+}
+
+// FailoverFactory is a TransportFactory that creates Transports to one of
+// several endpoints, each represented by its own TransportFactory.
+//
+// An endpoint that fails to create a Transport, or whose Transport fails the
+// handshake (see MarkFailed), is skipped for the FailoverOptions.Cooldown
+// period. If all endpoints are cooling down, all of
+// them are tried anyway.
+//
+// FailoverFactory implements RedirectTransportFactory, redirects are followed
+// by the factory of the endpoint that created the last Transport.
+type FailoverFactory[T any] struct {
+	mu        sync.Mutex
+	factories []TransportFactory[T]
+	downUntil []time.Time
+	last      int
+	options   FailoverOptions
+}
+
+func (f *FailoverFactory[T]) New() (transport Transport[T], err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var (
+		now           = time.Now()
+		healthy, down = f.split(f.order(), now)
+		errs          = make([]error, 0, len(f.factories))
+	)
+	for _, i := range append(healthy, down...) {
+		if transport, err = f.factories[i].New(); err == nil {
+			f.downUntil[i] = time.Time{}
+			f.last = i
+			return
+		}
+		f.downUntil[i] = time.Now().Add(f.options.Cooldown)
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// MarkFailed puts the endpoint that created the last Transport in cool-down,
+// as if it failed to create a Transport.
+func (f *FailoverFactory[T]) MarkFailed() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.last < 0 {
+		return
+	}
+	f.downUntil[f.last] = time.Now().Add(f.options.Cooldown)
+}
+
+// NewTo creates a Transport to addr with the factory of the endpoint that
+// created the last Transport. Returns ErrRedirectNotSupported if it is not a
+// RedirectTransportFactory.
+func (f *FailoverFactory[T]) NewTo(addr string) (transport Transport[T],
+	err error,
+) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.last < 0 {
+		return nil, ErrRedirectNotSupported
+	}
+	rfactory, ok := f.factories[f.last].(RedirectTransportFactory[T])
+	if !ok {
+		return nil, ErrRedirectNotSupported
+	}
+	return rfactory.NewTo(addr)
+}
+
+func (f *FailoverFactory[T]) order() (order []int) {
+	n := len(f.factories)
+	switch f.options.Policy {
+	case FailoverRandom:
+		return rand.Perm(n)
+	case FailoverRoundRobin:
+		return rotate(n, f.last+1)
+	case FailoverSticky:
+		if f.last >= 0 {
+			return rotate(n, f.last)
+		}
+	}
+	return rotate(n, 0)
+}
+
+// split separates endpoints that are cooling down, preserving the order.
+func (f *FailoverFactory[T]) split(order []int, now time.Time) (healthy,
+	down []int,
+) {
+	for _, i := range order {
+		if now.Before(f.downUntil[i]) {
+			down = append(down, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	return
+}
+
+func rotate(n, start int) (order []int) {
+	order = make([]int, n)
+	for i := range order {
+		order[i] = (start + i) % n
+	}
+	return
+}
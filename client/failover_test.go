@@ -0,0 +1,297 @@
+package client_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cmd-stream/delegate-go"
+	dcln "github.com/cmd-stream/delegate-go/client"
+	clnmock "github.com/cmd-stream/delegate-go/test/mock/client"
+	asserterror "github.com/ymz-ncnk/assert/error"
+	"github.com/ymz-ncnk/mok"
+)
+
+func TestFailoverFactory(t *testing.T) {
+	var (
+		transport1 = clnmock.NewTransport()
+		transport2 = clnmock.NewTransport()
+		dialErr    = errors.New("dial error")
+	)
+
+	t.Run("If factories is empty, NewFailoverFactory should panic",
+		func(t *testing.T) {
+			wantErr := dcln.ErrNoFactories
+			defer func() {
+				r := recover()
+				err, _ := r.(error)
+				asserterror.EqualError(t, err, wantErr)
+			}()
+			dcln.NewFailoverFactory[any](nil)
+		})
+
+	t.Run("FailoverOrdered should always start from the first endpoint",
+		func(t *testing.T) {
+			var (
+				factory1 = clnmock.NewTransportFactory().RegisterNNew(2,
+					func() (dcln.Transport[any], error) { return transport1, nil },
+				)
+				factory2 = clnmock.NewTransportFactory()
+				mocks    = []*mok.Mock{factory1.Mock, factory2.Mock}
+				f        = dcln.NewFailoverFactory([]dcln.TransportFactory[any]{
+					factory1, factory2,
+				})
+			)
+			for range 2 {
+				transport, err := f.New()
+				asserterror.EqualError(t, err, nil)
+				asserterror.Equal[dcln.Transport[any]](t, transport, transport1)
+			}
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("FailoverRoundRobin should rotate endpoints", func(t *testing.T) {
+		var (
+			factory1 = clnmock.NewTransportFactory().RegisterNNew(2,
+				func() (dcln.Transport[any], error) { return transport1, nil },
+			)
+			factory2 = clnmock.NewTransportFactory().RegisterNew(
+				func() (dcln.Transport[any], error) { return transport2, nil },
+			)
+			mocks = []*mok.Mock{factory1.Mock, factory2.Mock}
+			f     = dcln.NewFailoverFactory([]dcln.TransportFactory[any]{
+				factory1, factory2,
+			}, dcln.WithFailoverPolicy(dcln.FailoverRoundRobin))
+		)
+		for _, want := range []dcln.Transport[any]{
+			transport1, transport2, transport1,
+		} {
+			transport, err := f.New()
+			asserterror.EqualError(t, err, nil)
+			asserterror.Equal(t, transport, want)
+		}
+		asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+	})
+
+	t.Run("FailoverSticky should keep using the endpoint until it fails",
+		func(t *testing.T) {
+			var (
+				factory1 = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return nil, dialErr },
+				)
+				factory2 = clnmock.NewTransportFactory().RegisterNNew(2,
+					func() (dcln.Transport[any], error) { return transport2, nil },
+				)
+				mocks = []*mok.Mock{factory1.Mock, factory2.Mock}
+				f     = dcln.NewFailoverFactory([]dcln.TransportFactory[any]{
+					factory1, factory2,
+				}, dcln.WithFailoverPolicy(dcln.FailoverSticky))
+			)
+			for range 2 {
+				transport, err := f.New()
+				asserterror.EqualError(t, err, nil)
+				asserterror.Equal[dcln.Transport[any]](t, transport, transport2)
+			}
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("FailoverRandom should try all endpoints", func(t *testing.T) {
+		var (
+			factory1 = clnmock.NewTransportFactory().RegisterNew(
+				func() (dcln.Transport[any], error) { return nil, dialErr },
+			)
+			factory2 = clnmock.NewTransportFactory().RegisterNew(
+				func() (dcln.Transport[any], error) { return nil, dialErr },
+			)
+			mocks = []*mok.Mock{factory1.Mock, factory2.Mock}
+			f     = dcln.NewFailoverFactory([]dcln.TransportFactory[any]{
+				factory1, factory2,
+			}, dcln.WithFailoverPolicy(dcln.FailoverRandom))
+		)
+		_, err := f.New()
+		asserterror.EqualError(t, err, errors.Join(dialErr, dialErr))
+		asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+	})
+
+	t.Run("Failed endpoint should be skipped during the cooldown period",
+		func(t *testing.T) {
+			var (
+				cooldown = 100 * time.Millisecond
+				factory1 = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return nil, dialErr },
+				).RegisterNew(
+					func() (dcln.Transport[any], error) { return transport1, nil },
+				)
+				factory2 = clnmock.NewTransportFactory().RegisterNNew(2,
+					func() (dcln.Transport[any], error) { return transport2, nil },
+				)
+				mocks = []*mok.Mock{factory1.Mock, factory2.Mock}
+				f     = dcln.NewFailoverFactory([]dcln.TransportFactory[any]{
+					factory1, factory2,
+				}, dcln.WithFailoverCooldown(cooldown))
+			)
+			for range 2 {
+				transport, err := f.New()
+				asserterror.EqualError(t, err, nil)
+				asserterror.Equal[dcln.Transport[any]](t, transport, transport2)
+			}
+			time.Sleep(cooldown)
+			transport, err := f.New()
+			asserterror.EqualError(t, err, nil)
+			asserterror.Equal[dcln.Transport[any]](t, transport, transport1)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If all endpoints are cooling down, they should be tried anyway",
+		func(t *testing.T) {
+			var (
+				factory1 = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return nil, dialErr },
+				).RegisterNew(
+					func() (dcln.Transport[any], error) { return transport1, nil },
+				)
+				mocks = []*mok.Mock{factory1.Mock}
+				f     = dcln.NewFailoverFactory([]dcln.TransportFactory[any]{
+					factory1,
+				}, dcln.WithFailoverCooldown(time.Minute))
+			)
+			_, err := f.New()
+			asserterror.EqualError(t, err, errors.Join(dialErr))
+			transport, err := f.New()
+			asserterror.EqualError(t, err, nil)
+			asserterror.Equal[dcln.Transport[any]](t, transport, transport1)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("MarkFailed should put the last endpoint in cool-down",
+		func(t *testing.T) {
+			var (
+				factory1 = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return transport1, nil },
+				)
+				factory2 = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return transport2, nil },
+				)
+				mocks = []*mok.Mock{factory1.Mock, factory2.Mock}
+				f     = dcln.NewFailoverFactory([]dcln.TransportFactory[any]{
+					factory1, factory2,
+				}, dcln.WithFailoverPolicy(dcln.FailoverSticky),
+					dcln.WithFailoverCooldown(time.Minute))
+			)
+			f.MarkFailed() // no Transports yet, does nothing
+			transport, err := f.New()
+			asserterror.EqualError(t, err, nil)
+			asserterror.Equal[dcln.Transport[any]](t, transport, transport1)
+			f.MarkFailed()
+			transport, err = f.New()
+			asserterror.EqualError(t, err, nil)
+			asserterror.Equal[dcln.Transport[any]](t, transport, transport2)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If an endpoint rejects the client as busy, Reconnect should fail over to the next one",
+		func(t *testing.T) {
+			var (
+				busyTransport = clnmock.NewTransport().RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterReceiveAdmission(
+					func() (reason delegate.RejectReason, err error) {
+						return delegate.RejectBusy, nil
+					},
+				).RegisterClose(
+					func() (err error) { return nil },
+				)
+				wantTransport = clnmock.NewTransport().RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterReceiveAdmission(
+					func() (reason delegate.RejectReason, err error) {
+						return delegate.RejectNone, nil
+					},
+				).RegisterReceiveServerInfo(
+					func(maxSize int) (i delegate.ServerInfo, err error) {
+						return nil, nil
+					},
+				).RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				)
+				factory1 = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return busyTransport, nil },
+				)
+				factory2 = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return wantTransport, nil },
+				)
+				mocks = []*mok.Mock{busyTransport.Mock, wantTransport.Mock,
+					factory1.Mock, factory2.Mock}
+				f = dcln.NewFailoverFactory([]dcln.TransportFactory[any]{
+					factory1, factory2,
+				}, dcln.WithFailoverCooldown(time.Minute))
+				closeFlag uint32
+				delegate  = dcln.NewReconnectWithoutInfo[any](f, &closeFlag,
+					&atomic.Value{}, dcln.Options{Admission: true})
+			)
+			err := delegate.Reconnect()
+			asserterror.EqualError(t, err, nil)
+			if transport := delegate.Transport(); transport != wantTransport {
+				t.Errorf("unexpected transport, want '%v' actual '%v'", wantTransport,
+					transport)
+			}
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("NewTo should use the factory of the last endpoint", func(t *testing.T) {
+		var (
+			wantAddr = "127.0.0.1:9001"
+			factory1 = clnmock.NewTransportFactory().RegisterNew(
+				func() (dcln.Transport[any], error) { return nil, dialErr },
+			)
+			factory2 = clnmock.NewTransportFactory().RegisterNew(
+				func() (dcln.Transport[any], error) { return transport1, nil },
+			).RegisterNewTo(
+				func(addr string) (dcln.Transport[any], error) {
+					asserterror.Equal(t, addr, wantAddr)
+					return transport2, nil
+				},
+			)
+			mocks = []*mok.Mock{factory1.Mock, factory2.Mock}
+			f     = dcln.NewFailoverFactory([]dcln.TransportFactory[any]{
+				factory1, factory2,
+			})
+		)
+		_, err := f.New()
+		asserterror.EqualError(t, err, nil)
+		transport, err := f.NewTo(wantAddr)
+		asserterror.EqualError(t, err, nil)
+		asserterror.Equal[dcln.Transport[any]](t, transport, transport2)
+		asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+	})
+
+	t.Run("If the factory of the last endpoint can't follow redirects, NewTo should return ErrRedirectNotSupported",
+		func(t *testing.T) {
+			var (
+				factory1 = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return transport1, nil },
+				)
+				mocks = []*mok.Mock{factory1.Mock}
+				f     = dcln.NewFailoverFactory([]dcln.TransportFactory[any]{
+					newOnlyFactory{factory1},
+				})
+			)
+			_, err := f.NewTo("127.0.0.1:9001")
+			asserterror.EqualError(t, err, dcln.ErrRedirectNotSupported)
+			_, err = f.New()
+			asserterror.EqualError(t, err, nil)
+			_, err = f.NewTo("127.0.0.1:9001")
+			asserterror.EqualError(t, err, dcln.ErrRedirectNotSupported)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+}
+
+// newOnlyFactory hides NewTo of the wrapped factory.
+type newOnlyFactory struct {
+	factory dcln.TransportFactory[any]
+}
+
+func (f newOnlyFactory) New() (dcln.Transport[any], error) {
+	return f.factory.New()
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cmd-stream/core-go"
@@ -14,6 +15,12 @@ const (
 	KeepaliveIntvl = time.Second
 )
 
+const (
+	noTimeout uint32 = iota
+	timeoutOccurred
+	timeoutReported
+)
+
 // NewKeepalive creates a new KeepaliveDelegate.
 func NewKeepalive[T any](d ccln.Delegate[T], ops ...SetKeepaliveOption) (
 	kd KeepaliveDelegate[T],
@@ -26,6 +33,38 @@ func NewKeepalive[T any](d ccln.Delegate[T], ops ...SetKeepaliveOption) (
 	kd.Delegate = d
 	kd.alive = make(chan struct{})
 	kd.done = make(chan struct{})
+	kd.missed = new(uint32)
+	kd.timeout = new(uint32)
+	kd.rtt = newRTTRecorder()
+	return
+}
+
+// NewReconnectKeepalive creates a new ReconnectKeepaliveDelegate.
+func NewReconnectKeepalive[T any](d ccln.ReconnectDelegate[T],
+	ops ...SetKeepaliveOption,
+) ReconnectKeepaliveDelegate[T] {
+	return ReconnectKeepaliveDelegate[T]{
+		KeepaliveDelegate: NewKeepalive[T](d, ops...),
+		reconnect:         d,
+	}
+}
+
+// ReconnectKeepaliveDelegate is a KeepaliveDelegate, which implements the
+// core.ClientReconnectDelegate interface, so the client reconnects after a
+// keepalive timeout instead of being closed.
+type ReconnectKeepaliveDelegate[T any] struct {
+	KeepaliveDelegate[T]
+	reconnect ccln.ReconnectDelegate[T]
+}
+
+// Reconnect calls Reconnect of the wrapped delegate. After a successful
+// reconnection, the keepalive timeout is detected anew.
+func (d ReconnectKeepaliveDelegate[T]) Reconnect() (err error) {
+	if err = d.reconnect.Reconnect(); err != nil {
+		return
+	}
+	atomic.StoreUint32(d.missed, 0)
+	atomic.StoreUint32(d.timeout, noTimeout)
 	return
 }
 
@@ -34,10 +73,18 @@ func NewKeepalive[T any](d ccln.Delegate[T], ops ...SetKeepaliveOption) (
 // When there are no Commands to send, it initiates a Ping-Pong exchange with
 // the server. It sends a Ping Command and expects a Pong Result, both
 // represented as a single zero byte (like a ball being passed).
+//
+// If KeepaliveCnt is set and the server does not respond to that many Ping
+// Commands in a row, the delegate closes the underlying connection and
+// Receive returns ErrKeepaliveTimeout. To reconnect after that, use
+// ReconnectKeepaliveDelegate.
 type KeepaliveDelegate[T any] struct {
 	ccln.Delegate[T]
 	alive   chan struct{}
 	done    chan struct{}
+	missed  *uint32
+	timeout *uint32
+	rtt     *rttRecorder
 	options KeepaliveOptions
 }
 
@@ -47,14 +94,26 @@ func (d KeepaliveDelegate[T]) Receive() (seq core.Seq, result core.Result,
 Start:
 	seq, result, n, err = d.Delegate.Receive()
 	if err != nil {
+		if atomic.CompareAndSwapUint32(d.timeout, timeoutOccurred,
+			timeoutReported) {
+			err = ErrKeepaliveTimeout
+		}
 		return
 	}
+	atomic.StoreUint32(d.missed, 0)
 	if _, ok := result.(delegate.PongResult); ok {
+		d.rtt.pongReceived(time.Now())
 		goto Start
 	}
 	return
 }
 
+// Stats returns round-trip time statistics measured by the Ping-Pong
+// exchange.
+func (d KeepaliveDelegate[T]) Stats() KeepaliveStats {
+	return d.rtt.Stats()
+}
+
 func (d KeepaliveDelegate[T]) Flush() (err error) {
 	if err = d.Delegate.Flush(); err != nil {
 		return
@@ -68,7 +127,11 @@ func (d KeepaliveDelegate[T]) Flush() (err error) {
 
 func (d KeepaliveDelegate[T]) Close() (err error) {
 	if err = d.Delegate.Close(); err != nil {
-		return
+		// The connection may have already been closed on keepalive timeout.
+		if atomic.LoadUint32(d.timeout) == noTimeout {
+			return
+		}
+		err = nil
 	}
 	close(d.done)
 	return
@@ -85,7 +148,12 @@ func keepalive[T any](d KeepaliveDelegate[T], muSn *sync.Mutex) {
 		case <-d.done:
 			return
 		case <-timer.C:
-			ping(muSn, 0, d) // nothing to do if ping returns an error, TODO
+			if d.deadPeer() {
+				closeOnTimeout(d)
+			} else if _, err := ping(muSn, 0, d); err == nil &&
+				d.options.KeepaliveCnt > 0 {
+				atomic.AddUint32(d.missed, 1)
+			}
 			timer.Reset(d.options.KeepaliveIntvl)
 		case <-d.alive:
 			if !timer.Stop() {
@@ -96,6 +164,31 @@ func keepalive[T any](d KeepaliveDelegate[T], muSn *sync.Mutex) {
 	}
 }
 
+func (d KeepaliveDelegate[T]) deadPeer() bool {
+	return d.options.KeepaliveCnt > 0 &&
+		atomic.LoadUint32(d.missed) >= uint32(d.options.KeepaliveCnt)
+}
+
+// transportHolder is implemented by delegates, like ReconnectDelegate, that
+// can replace their Transport.
+type transportHolder[T any] interface {
+	Transport() Transport[T]
+}
+
+// closeOnTimeout closes the underlying connection, so that the client
+// receives an error and may reconnect. If the delegate is a transportHolder,
+// only its current Transport is closed and the delegate itself stays usable.
+func closeOnTimeout[T any](d KeepaliveDelegate[T]) {
+	atomic.StoreUint32(d.missed, 0)
+	atomic.StoreUint32(d.timeout, timeoutOccurred)
+	d.rtt.reset()
+	if h, ok := d.Delegate.(transportHolder[T]); ok {
+		h.Transport().Close()
+		return
+	}
+	d.Delegate.Close()
+}
+
 func ping[T any](muSn *sync.Mutex, seq core.Seq, d KeepaliveDelegate[T]) (
 	n int, err error,
 ) {
@@ -108,6 +201,10 @@ func ping[T any](muSn *sync.Mutex, seq core.Seq, d KeepaliveDelegate[T]) (
 		muSn.Unlock()
 		return
 	}
+	d.rtt.pingSent(time.Now())
 	muSn.Unlock()
-	return n, d.Delegate.Flush()
+	if err = d.Delegate.Flush(); err != nil {
+		d.rtt.pingFailed()
+	}
+	return
 }
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// rttEWMAWeight is the weight of a new sample in the RTT exponentially
+	// weighted moving average, the same as used by TCP for SRTT.
+	rttEWMAWeight = 0.125
+	// maxPendingPings limits the number of Ping timestamps kept while waiting
+	// for Pongs, so a server that never responds does not cause memory growth.
+	maxPendingPings = 16
+)
+
+// KeepaliveStats contains round-trip time statistics collected from the
+// Ping-Pong exchange.
+type KeepaliveStats struct {
+	Samples int
+	LastRTT time.Duration
+	MinRTT  time.Duration
+	AvgRTT  time.Duration
+	EWMARTT time.Duration
+}
+
+func newRTTRecorder() *rttRecorder {
+	return &rttRecorder{pending: make([]time.Time, 0, maxPendingPings)}
+}
+
+// rttRecorder matches Pongs with previously sent Pings. Pongs are received
+// in the same order as Pings are sent, so the oldest pending Ping corresponds
+// to the received Pong.
+type rttRecorder struct {
+	mu      sync.Mutex
+	pending []time.Time
+	total   time.Duration
+	stats   KeepaliveStats
+}
+
+func (r *rttRecorder) pingSent(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) == maxPendingPings {
+		r.pending = append(r.pending[:0], r.pending[1:]...)
+	}
+	r.pending = append(r.pending, t)
+}
+
+func (r *rttRecorder) pingFailed() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) > 0 {
+		r.pending = r.pending[:len(r.pending)-1]
+	}
+}
+
+func (r *rttRecorder) pongReceived(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) == 0 {
+		return
+	}
+	rtt := t.Sub(r.pending[0])
+	r.pending = append(r.pending[:0], r.pending[1:]...)
+
+	r.stats.Samples++
+	r.total += rtt
+	r.stats.LastRTT = rtt
+	r.stats.AvgRTT = r.total / time.Duration(r.stats.Samples)
+	if r.stats.Samples == 1 {
+		r.stats.MinRTT = rtt
+		r.stats.EWMARTT = rtt
+		return
+	}
+	if rtt < r.stats.MinRTT {
+		r.stats.MinRTT = rtt
+	}
+	r.stats.EWMARTT += time.Duration(rttEWMAWeight *
+		float64(rtt-r.stats.EWMARTT))
+}
+
+// reset forgets pending Pings, for example, when the connection was closed.
+func (r *rttRecorder) reset() {
+	r.mu.Lock()
+	r.pending = r.pending[:0]
+	r.mu.Unlock()
+}
+
+func (r *rttRecorder) Stats() KeepaliveStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	asserterror "github.com/ymz-ncnk/assert/error"
+)
+
+func TestRTTRecorder(t *testing.T) {
+	t.Run("Pongs should be matched with Pings in order", func(t *testing.T) {
+		var (
+			r     = newRTTRecorder()
+			start = time.Now()
+		)
+		r.pingSent(start)
+		r.pingSent(start.Add(time.Second))
+		r.pongReceived(start.Add(100 * time.Millisecond))
+		r.pongReceived(start.Add(time.Second + 300*time.Millisecond))
+
+		stats := r.Stats()
+		asserterror.Equal(t, stats.Samples, 2)
+		asserterror.Equal(t, stats.LastRTT, 300*time.Millisecond)
+		asserterror.Equal(t, stats.MinRTT, 100*time.Millisecond)
+		asserterror.Equal(t, stats.AvgRTT, 200*time.Millisecond)
+		asserterror.Equal(t, stats.EWMARTT, 125*time.Millisecond)
+	})
+
+	t.Run("Pong without Ping should be ignored", func(t *testing.T) {
+		r := newRTTRecorder()
+		r.pongReceived(time.Now())
+		asserterror.Equal(t, r.Stats(), KeepaliveStats{})
+	})
+
+	t.Run("Failed Ping should not be matched", func(t *testing.T) {
+		var (
+			r     = newRTTRecorder()
+			start = time.Now()
+		)
+		r.pingSent(start)
+		r.pingSent(start.Add(time.Second))
+		r.pingFailed()
+		r.pongReceived(start.Add(2 * time.Second))
+		r.pongReceived(start.Add(3 * time.Second))
+		asserterror.Equal(t, r.Stats().Samples, 1)
+		asserterror.Equal(t, r.Stats().LastRTT, 2*time.Second)
+	})
+
+	t.Run("Number of pending Pings should be limited", func(t *testing.T) {
+		var (
+			r     = newRTTRecorder()
+			start = time.Now()
+		)
+		for i := 0; i < maxPendingPings+1; i++ {
+			r.pingSent(start.Add(time.Duration(i) * time.Second))
+		}
+		asserterror.Equal(t, len(r.pending), maxPendingPings)
+		r.pongReceived(start.Add(time.Duration(maxPendingPings+1) * time.Second))
+		asserterror.Equal(t, r.Stats().LastRTT, time.Duration(maxPendingPings)*time.Second)
+	})
+}
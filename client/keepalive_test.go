@@ -295,4 +295,147 @@ func TestKeepaliveDelegate(t *testing.T) {
 			asserterror.EqualError(t, err, nil)
 			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
 		})
+
+	t.Run("If the server does not respond to Pings, KeepaliveDelegate should close the connection and Receive should return ErrKeepaliveTimeout",
+		func(t *testing.T) {
+			var (
+				closed    = make(chan struct{})
+				wantErr   = ErrKeepaliveTimeout
+				wantN     = 0
+				receiveCh = make(chan struct{})
+				d         = cclnmock.NewDelegate().RegisterSetSendDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterSend(
+					func(seq core.Seq, cmd core.Cmd[any]) (n int, err error) {
+						return 1, nil
+					},
+				).RegisterFlush(
+					func() (err error) { return nil },
+				).RegisterClose(
+					func() (err error) { defer close(closed); return nil },
+				).RegisterReceive(
+					func() (seq core.Seq, result core.Result, n int, err error) {
+						<-receiveCh
+						return 0, nil, 0, errors.New("use of closed connection")
+					},
+				).RegisterClose(
+					func() (err error) { return errors.New("already closed") },
+				)
+				mocks = []*mok.Mock{d.Mock}
+				dlgt  = NewKeepalive(d,
+					WithKeepaliveTime(100*time.Millisecond),
+					WithKeepaliveIntvl(200*time.Millisecond),
+					WithKeepaliveCnt(1),
+				)
+			)
+			dlgt.Keepalive(&sync.Mutex{})
+
+			select {
+			case <-closed:
+			case <-time.NewTimer(time.Second).C:
+				t.Fatal("test lasts too long")
+			}
+			close(receiveCh)
+
+			_, _, n, err := dlgt.Receive()
+			asserterror.Equal(t, n, wantN)
+			asserterror.EqualError(t, err, wantErr)
+
+			err = dlgt.Close()
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("Received Results should reset the number of unanswered Pings",
+		func(t *testing.T) {
+			var (
+				done = make(chan struct{})
+				d    = cclnmock.NewDelegate().RegisterNSetSendDeadline(2,
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterSend(
+					func(seq core.Seq, cmd core.Cmd[any]) (n int, err error) {
+						return 1, nil
+					},
+				).RegisterFlush(
+					func() (err error) { return nil },
+				).RegisterReceive(
+					func() (seq core.Seq, result core.Result, n int, err error) {
+						return 0, delegate.PongResult{}, 1, nil
+					},
+				).RegisterReceive(
+					func() (seq core.Seq, result core.Result, n int, err error) {
+						<-done
+						return 0, nil, 0, errors.New("receive error")
+					},
+				).RegisterSend(
+					func(seq core.Seq, cmd core.Cmd[any]) (n int, err error) {
+						return 1, nil
+					},
+				).RegisterFlush(
+					func() (err error) { defer close(done); return nil },
+				).RegisterClose(
+					func() (err error) { return nil },
+				)
+				mocks = []*mok.Mock{d.Mock}
+				dlgt  = NewKeepalive(d,
+					WithKeepaliveTime(100*time.Millisecond),
+					WithKeepaliveIntvl(200*time.Millisecond),
+					WithKeepaliveCnt(1),
+				)
+			)
+			dlgt.Keepalive(&sync.Mutex{})
+			time.Sleep(200 * time.Millisecond)
+
+			_, _, _, err := dlgt.Receive()
+			asserterror.EqualError(t, err, errors.New("receive error"))
+
+			err = dlgt.Close()
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("Stats should contain the RTT of the Ping-Pong exchange",
+		func(t *testing.T) {
+			var (
+				pingSent = make(chan struct{})
+				rtt      = 100 * time.Millisecond
+				d        = cclnmock.NewDelegate().RegisterSetSendDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterSend(
+					func(seq core.Seq, cmd core.Cmd[any]) (n int, err error) {
+						return 1, nil
+					},
+				).RegisterFlush(
+					func() (err error) { defer close(pingSent); return nil },
+				).RegisterReceive(
+					func() (seq core.Seq, result core.Result, n int, err error) {
+						<-pingSent
+						time.Sleep(rtt)
+						return 0, delegate.PongResult{}, 1, nil
+					},
+				).RegisterReceive(
+					func() (seq core.Seq, result core.Result, n int, err error) {
+						return 0, nil, 0, errors.New("receive error")
+					},
+				).RegisterClose(
+					func() (err error) { return nil },
+				)
+				mocks = []*mok.Mock{d.Mock}
+				dlgt  = NewKeepalive(d,
+					WithKeepaliveTime(100*time.Millisecond),
+					WithKeepaliveIntvl(time.Second),
+				)
+			)
+			dlgt.Keepalive(&sync.Mutex{})
+			dlgt.Receive()
+
+			stats := dlgt.Stats()
+			asserterror.Equal(t, stats.Samples, 1)
+			asserterror.SameTime(t, time.Time{}.Add(stats.LastRTT),
+				time.Time{}.Add(rtt), delta)
+
+			err := dlgt.Close()
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
 }
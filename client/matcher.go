@@ -0,0 +1,65 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cmd-stream/delegate-go"
+)
+
+// ServerInfoMatcher decides whether the ServerInfo received from the server
+// is acceptable.
+//
+// Match should return an error wrapping ErrServerInfoMismatch if the server
+// is incompatible. Other errors are treated as handshake failures, so
+// ReconnectDelegate will try again.
+type ServerInfoMatcher interface {
+	Match(want, got delegate.ServerInfo) error
+}
+
+// ExactMatcher accepts only ServerInfo that is byte-for-byte equal to the
+// expected one. It is used by default.
+type ExactMatcher struct{}
+
+func (m ExactMatcher) Match(want, got delegate.ServerInfo) error {
+	if !bytes.Equal(want, got) {
+		return ErrServerInfoMismatch
+	}
+	return nil
+}
+
+// NewVersionRangeMatcher creates a new VersionRangeMatcher.
+func NewVersionRangeMatcher(r delegate.VersionRange) VersionRangeMatcher {
+	return VersionRangeMatcher{r: r}
+}
+
+// VersionRangeMatcher treats ServerInfo as delegate.VersionedInfo. It accepts
+// a server of the same service whose version falls within the range and which
+// supports all the expected features.
+type VersionRangeMatcher struct {
+	r delegate.VersionRange
+}
+
+func (m VersionRangeMatcher) Match(want, got delegate.ServerInfo) (
+	err error,
+) {
+	wantInfo, err := delegate.ParseVersionedInfo(want)
+	if err != nil {
+		return
+	}
+	gotInfo, err := delegate.ParseVersionedInfo(got)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrServerInfoMismatch, err)
+	}
+	switch {
+	case gotInfo.Service != wantInfo.Service:
+		return fmt.Errorf("%w: unexpected service %q", ErrServerInfoMismatch,
+			gotInfo.Service)
+	case !m.r.Contains(gotInfo.Version):
+		return fmt.Errorf("%w: unsupported version %v", ErrServerInfoMismatch,
+			gotInfo.Version)
+	case !gotInfo.HasFeatures(wantInfo.Features):
+		return fmt.Errorf("%w: missing features", ErrServerInfoMismatch)
+	}
+	return
+}
@@ -0,0 +1,21 @@
+package client
+
+import "net"
+
+// ReconnectObserver receives ReconnectDelegate lifecycle events.
+//
+// All methods are invoked synchronously from Reconnect, so they should
+// return quickly.
+type ReconnectObserver interface {
+	// OnDisconnect is called when reconnecting starts, i.e. the connection to
+	// the server was lost.
+	OnDisconnect()
+	// OnAttempt is called before each reconnect attempt, attempt starts from 1.
+	OnAttempt(attempt int)
+	// OnAttemptFailed is called when a dial or handshake attempt fails.
+	OnAttemptFailed(err error)
+	// OnReconnected is called when a new connection has been established.
+	OnReconnected(localAddr, remoteAddr net.Addr)
+	// OnGiveUp is called when Reconnect stops trying and returns an error.
+	OnGiveUp(err error)
+}
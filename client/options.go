@@ -1,19 +1,114 @@
 package client
 
-import "time"
+import (
+	"time"
+
+	"github.com/cmd-stream/delegate-go"
+)
 
 type Options struct {
 	ServerInfoReceiveDuration time.Duration
+	Backoff                   Backoff
+	MaxReconnectAttempts      int
+	ReconnectDuration         time.Duration
+	ReconnectObserver         ReconnectObserver
+	ServerInfoMatcher         ServerInfoMatcher
+	ClientInfo                delegate.ClientInfo
+	AuthKey                   delegate.AuthKey
+	MaxServerInfoSize         int
+	Preamble                  bool
+	Admission                 bool
+	MaxRedirects              int
 }
 
 type SetOption func(o *Options)
 
 // WithServerInfoReceiveDuration sets the duration the client will wait
-// for the ServerInfo. If set to 0, the client waits indefinitely.
+// for the ServerInfo and the authentication challenge. If set to 0, the
+// client waits indefinitely.
 func WithServerInfoReceiveDuration(d time.Duration) SetOption {
 	return func(o *Options) { o.ServerInfoReceiveDuration = d }
 }
 
+// WithBackoff sets the Backoff used by ReconnectDelegate between failed
+// reconnect attempts. If not set, attempts are made without delay. Closing the
+// delegate interrupts the delay.
+func WithBackoff(b Backoff) SetOption {
+	return func(o *Options) { o.Backoff = b }
+}
+
+// WithMaxReconnectAttempts sets the maximum number of attempts
+// ReconnectDelegate makes before giving up. If set to 0, the number of
+// attempts is not limited.
+func WithMaxReconnectAttempts(n int) SetOption {
+	return func(o *Options) { o.MaxReconnectAttempts = n }
+}
+
+// WithReconnectDuration sets the total time ReconnectDelegate spends on
+// reconnecting before giving up. If set to 0, the time is not limited.
+func WithReconnectDuration(d time.Duration) SetOption {
+	return func(o *Options) { o.ReconnectDuration = d }
+}
+
+// WithReconnectObserver sets the ReconnectObserver that is notified about
+// ReconnectDelegate lifecycle events.
+func WithReconnectObserver(observer ReconnectObserver) SetOption {
+	return func(o *Options) { o.ReconnectObserver = observer }
+}
+
+// WithServerInfoMatcher sets the ServerInfoMatcher used to check the received
+// ServerInfo. Defaults to ExactMatcher.
+func WithServerInfoMatcher(m ServerInfoMatcher) SetOption {
+	return func(o *Options) { o.ServerInfoMatcher = m }
+}
+
+// WithServerVersionRange is a shortcut for WithServerInfoMatcher with
+// VersionRangeMatcher.
+func WithServerVersionRange(r delegate.VersionRange) SetOption {
+	return WithServerInfoMatcher(NewVersionRangeMatcher(r))
+}
+
+// WithClientInfo sets ClientInfo, which the client sends to the server after
+// ServerInfo has been accepted. The server must be configured to receive it.
+func WithClientInfo(info delegate.ClientInfo) SetOption {
+	return func(o *Options) { o.ClientInfo = info }
+}
+
+// WithAuthKey sets the shared secret used to answer the server's
+// authentication challenge. The server must be configured with the same key.
+func WithAuthKey(key delegate.AuthKey) SetOption {
+	return func(o *Options) { o.AuthKey = key }
+}
+
+// WithMaxServerInfoSize sets the maximum size of ServerInfo the client
+// accepts, a bigger one causes delegate.ErrServerInfoTooLarge. The limit is
+// passed to Transport.ReceiveServerInfo, so oversized ServerInfo is rejected
+// before memory for it is allocated. If set to 0, the size is not limited.
+func WithMaxServerInfoSize(size int) SetOption {
+	return func(o *Options) { o.MaxServerInfoSize = size }
+}
+
+// WithPreamble makes the client expect delegate.Preamble before ServerInfo.
+// The server must be configured to send it.
+func WithPreamble() SetOption {
+	return func(o *Options) { o.Preamble = true }
+}
+
+// WithAdmission makes the client expect the admission frame after the
+// Preamble. If the server rejects the connection, the client fails with
+// RejectedError. The server must be configured to send it.
+func WithAdmission() SetOption {
+	return func(o *Options) { o.Admission = true }
+}
+
+// WithMaxRedirects sets how many redirects in a row ReconnectDelegate
+// follows, which protects against redirect loops. It requires the WithAdmission
+// option and a RedirectTransportFactory. If set to 0, redirects are not
+// followed and cause RedirectError.
+func WithMaxRedirects(n int) SetOption {
+	return func(o *Options) { o.MaxRedirects = n }
+}
+
 func Apply(ops []SetOption, o *Options) {
 	for i := range ops {
 		if ops[i] != nil {
@@ -25,6 +120,7 @@ func Apply(ops []SetOption, o *Options) {
 type KeepaliveOptions struct {
 	KeepaliveTime  time.Duration
 	KeepaliveIntvl time.Duration
+	KeepaliveCnt   int
 }
 
 type SetKeepaliveOption func(o *KeepaliveOptions)
@@ -41,6 +137,13 @@ func WithKeepaliveIntvl(d time.Duration) SetKeepaliveOption {
 	return func(o *KeepaliveOptions) { o.KeepaliveIntvl = d }
 }
 
+// WithKeepaliveCnt sets the number of consecutive unanswered Ping Commands
+// after which the server is considered dead and the connection is closed.
+// If set to 0, unanswered Pings are ignored.
+func WithKeepaliveCnt(n int) SetKeepaliveOption {
+	return func(o *KeepaliveOptions) { o.KeepaliveCnt = n }
+}
+
 func ApplyKeepAlive(ops []SetKeepaliveOption, o *KeepaliveOptions) {
 	for i := range ops {
 		if ops[i] != nil {
@@ -48,3 +151,30 @@ func ApplyKeepAlive(ops []SetKeepaliveOption, o *KeepaliveOptions) {
 		}
 	}
 }
+
+type FailoverOptions struct {
+	Policy   FailoverPolicy
+	Cooldown time.Duration
+}
+
+type SetFailoverOption func(o *FailoverOptions)
+
+// WithFailoverPolicy sets the order in which FailoverFactory tries endpoints.
+// Defaults to FailoverOrdered.
+func WithFailoverPolicy(p FailoverPolicy) SetFailoverOption {
+	return func(o *FailoverOptions) { o.Policy = p }
+}
+
+// WithFailoverCooldown sets the period during which a failed endpoint is
+// skipped. If set to 0, failed endpoints are not skipped.
+func WithFailoverCooldown(d time.Duration) SetFailoverOption {
+	return func(o *FailoverOptions) { o.Cooldown = d }
+}
+
+func ApplyFailover(ops []SetFailoverOption, o *FailoverOptions) {
+	for i := range ops {
+		if ops[i] != nil {
+			ops[i](o)
+		}
+	}
+}
@@ -1,23 +1,94 @@
 package client
 
 import (
+	"bytes"
 	"testing"
 	"time"
+
+	"github.com/cmd-stream/delegate-go"
 )
 
 func TestOptions(t *testing.T) {
 	var (
 		o                             = Options{}
 		wantServerInfoReceiveDuration = time.Second
+		wantBackoff                   = NewConstantBackoff(time.Second)
+		wantMaxReconnectAttempts      = 3
+		wantReconnectDuration         = time.Minute
+		wantServerVersionRange        = delegate.VersionRange{
+			Min: delegate.Version{Major: 1},
+		}
+		wantClientInfo        = delegate.ClientInfo("client info")
+		wantAuthKey           = delegate.AuthKey{ID: "1", Secret: []byte("secret")}
+		wantMaxServerInfoSize = 1024
+		wantMaxRedirects      = 2
 	)
 	Apply([]SetOption{
 		WithServerInfoReceiveDuration(wantServerInfoReceiveDuration),
+		WithBackoff(wantBackoff),
+		WithMaxReconnectAttempts(wantMaxReconnectAttempts),
+		WithReconnectDuration(wantReconnectDuration),
+		WithServerVersionRange(wantServerVersionRange),
+		WithClientInfo(wantClientInfo),
+		WithAuthKey(wantAuthKey),
+		WithMaxServerInfoSize(wantMaxServerInfoSize),
+		WithPreamble(),
+		WithAdmission(),
+		WithMaxRedirects(wantMaxRedirects),
 	}, &o)
 
 	if o.ServerInfoReceiveDuration != wantServerInfoReceiveDuration {
 		t.Errorf("unexpected ServerInfoReceiveDuration, want %v actual %v",
 			wantServerInfoReceiveDuration, o.ServerInfoReceiveDuration)
 	}
+
+	if o.Backoff != wantBackoff {
+		t.Errorf("unexpected Backoff, want %v actual %v", wantBackoff, o.Backoff)
+	}
+
+	if o.MaxReconnectAttempts != wantMaxReconnectAttempts {
+		t.Errorf("unexpected MaxReconnectAttempts, want %v actual %v",
+			wantMaxReconnectAttempts, o.MaxReconnectAttempts)
+	}
+
+	if o.ReconnectDuration != wantReconnectDuration {
+		t.Errorf("unexpected ReconnectDuration, want %v actual %v",
+			wantReconnectDuration, o.ReconnectDuration)
+	}
+
+	wantServerInfoMatcher := NewVersionRangeMatcher(wantServerVersionRange)
+	if o.ServerInfoMatcher != wantServerInfoMatcher {
+		t.Errorf("unexpected ServerInfoMatcher, want %v actual %v",
+			wantServerInfoMatcher, o.ServerInfoMatcher)
+	}
+
+	if !bytes.Equal(o.ClientInfo, wantClientInfo) {
+		t.Errorf("unexpected ClientInfo, want %v actual %v", wantClientInfo,
+			o.ClientInfo)
+	}
+
+	if o.AuthKey.ID != wantAuthKey.ID ||
+		!bytes.Equal(o.AuthKey.Secret, wantAuthKey.Secret) {
+		t.Errorf("unexpected AuthKey, want %v actual %v", wantAuthKey, o.AuthKey)
+	}
+
+	if o.MaxServerInfoSize != wantMaxServerInfoSize {
+		t.Errorf("unexpected MaxServerInfoSize, want %v actual %v",
+			wantMaxServerInfoSize, o.MaxServerInfoSize)
+	}
+
+	if !o.Preamble {
+		t.Error("unexpected Preamble, want true actual false")
+	}
+
+	if !o.Admission {
+		t.Error("unexpected Admission, want true actual false")
+	}
+
+	if o.MaxRedirects != wantMaxRedirects {
+		t.Errorf("unexpected MaxRedirects, want %v actual %v", wantMaxRedirects,
+			o.MaxRedirects)
+	}
 }
 
 func TestKeepAliveOptions(t *testing.T) {
@@ -25,10 +96,12 @@ func TestKeepAliveOptions(t *testing.T) {
 		o                  = KeepaliveOptions{}
 		wantKeepaliveTime  = 2 * time.Second
 		wantKeepaliveIntvl = 3 * time.Second
+		wantKeepaliveCnt   = 4
 	)
 	ApplyKeepAlive([]SetKeepaliveOption{
 		WithKeepaliveTime(wantKeepaliveTime),
 		WithKeepaliveIntvl(wantKeepaliveIntvl),
+		WithKeepaliveCnt(wantKeepaliveCnt),
 	}, &o)
 
 	if o.KeepaliveTime != wantKeepaliveTime {
@@ -40,4 +113,30 @@ func TestKeepAliveOptions(t *testing.T) {
 		t.Errorf("unexpected KeepaliveIntvl, want %v actual %v", wantKeepaliveIntvl,
 			o.KeepaliveIntvl)
 	}
+
+	if o.KeepaliveCnt != wantKeepaliveCnt {
+		t.Errorf("unexpected KeepaliveCnt, want %v actual %v", wantKeepaliveCnt,
+			o.KeepaliveCnt)
+	}
+}
+
+func TestFailoverOptions(t *testing.T) {
+	var (
+		o            = FailoverOptions{}
+		wantPolicy   = FailoverSticky
+		wantCooldown = time.Second
+	)
+	ApplyFailover([]SetFailoverOption{
+		WithFailoverPolicy(wantPolicy),
+		WithFailoverCooldown(wantCooldown),
+	}, &o)
+
+	if o.Policy != wantPolicy {
+		t.Errorf("unexpected Policy, want %v actual %v", wantPolicy, o.Policy)
+	}
+
+	if o.Cooldown != wantCooldown {
+		t.Errorf("unexpected Cooldown, want %v actual %v", wantCooldown,
+			o.Cooldown)
+	}
 }
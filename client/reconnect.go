@@ -1,6 +1,9 @@
 package client
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net"
 	"sync/atomic"
 	"time"
@@ -14,12 +17,8 @@ import (
 func NewReconnect[T any](info delegate.ServerInfo, factory TransportFactory[T],
 	ops ...SetOption,
 ) (d ReconnectDelegate[T], err error) {
-	transport, err := factory.New()
-	if err != nil {
-		return
-	}
 	Apply(ops, &d.options)
-	err = checkServerInfo(d.options.ServerInfoReceiveDuration, transport, info)
+	transport, err := connect(factory, info, d.options)
 	if err != nil {
 		return
 	}
@@ -27,6 +26,7 @@ func NewReconnect[T any](info delegate.ServerInfo, factory TransportFactory[T],
 	d.info = info
 	d.factory = factory
 	d.closedFlag = &closedFlag
+	d.done = make(chan struct{})
 	d.transport = &atomic.Value{}
 	d.setTransport(transport)
 	return
@@ -41,6 +41,7 @@ func NewReconnectWithoutInfo[T any](factory TransportFactory[T],
 	return ReconnectDelegate[T]{
 		factory:    factory,
 		closedFlag: closedFlag,
+		done:       make(chan struct{}),
 		transport:  transport,
 		options:    options,
 	}
@@ -51,6 +52,7 @@ type ReconnectDelegate[T any] struct {
 	info       delegate.ServerInfo
 	factory    TransportFactory[T]
 	closedFlag *uint32
+	done       chan struct{}
 	transport  *atomic.Value
 	options    Options
 }
@@ -91,39 +93,162 @@ func (d ReconnectDelegate[T]) Receive() (seq core.Seq, result core.Result,
 	return d.Transport().Receive()
 }
 
+// Close stops reconnecting and closes the current Transport. The delegate is
+// closed even if Transport.Close fails, for example, because the Transport
+// has already been closed by KeepaliveDelegate. Subsequent calls return nil.
 func (d ReconnectDelegate[T]) Close() (err error) {
-	err = d.Transport().Close()
+	if !atomic.CompareAndSwapUint32(d.closedFlag, 0, 1) {
+		return
+	}
+	close(d.done)
+	return d.Transport().Close()
+}
+
+// Reconnect is equivalent to ReconnectContext with the background context.
+func (d ReconnectDelegate[T]) Reconnect() (err error) {
+	return d.ReconnectContext(context.Background())
+}
+
+// ReconnectContext tries to establish a new connection to the server until
+// it succeeds, the delegate is closed, or one of the limits is reached.
+//
+// Returns ReconnectError if the context is done, the reconnect duration has
+// expired or the maximum number of attempts has been made. The context is
+// checked between attempts, TransportFactory.New is not interrupted.
+//
+// A ServerInfo mismatch or a non-retryable RejectedError stops reconnecting
+// immediately and is returned as is.
+func (d ReconnectDelegate[T]) ReconnectContext(ctx context.Context) (
+	err error,
+) {
+	observer := d.options.ReconnectObserver
+	if observer != nil {
+		observer.OnDisconnect()
+	}
+	transport, err := d.reconnect(ctx)
 	if err != nil {
+		if observer != nil {
+			observer.OnGiveUp(err)
+		}
 		return
 	}
-	if swapped := atomic.CompareAndSwapUint32(d.closedFlag, 0, 1); !swapped {
-		panic("can'transport close")
+	d.setTransport(transport)
+	if observer != nil {
+		observer.OnReconnected(transport.LocalAddr(), transport.RemoteAddr())
 	}
 	return
 }
 
-func (d ReconnectDelegate[T]) Reconnect() (err error) {
-	var transport Transport[T]
-Start:
+func (d ReconnectDelegate[T]) reconnect(ctx context.Context) (
+	transport Transport[T], err error,
+) {
+	if d.options.ReconnectDuration != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.options.ReconnectDuration)
+		defer cancel()
+	}
+	var (
+		observer = d.options.ReconnectObserver
+		attempt  int
+		delay    time.Duration
+		lastErr  error
+	)
 	for {
 		if d.closed() {
-			return cln.ErrClosed
+			return nil, cln.ErrClosed
 		}
-		transport, err = d.factory.New()
-		if err != nil {
-			continue
+		if err = ctx.Err(); err != nil {
+			return nil, NewReconnectError(attempt, err, lastErr)
+		}
+		if d.options.MaxReconnectAttempts != 0 &&
+			attempt >= d.options.MaxReconnectAttempts {
+			return nil, NewReconnectError(attempt, ErrMaxReconnectAttempts, lastErr)
+		}
+		if attempt > 0 && d.options.Backoff != nil {
+			delay = d.options.Backoff.Next(attempt, delay)
+			if err = sleep(ctx, d.done, delay); err != nil {
+				if err == cln.ErrClosed {
+					return nil, err
+				}
+				return nil, NewReconnectError(attempt, err, lastErr)
+			}
+			if d.closed() {
+				return nil, cln.ErrClosed
+			}
+		}
+		attempt++
+		if observer != nil {
+			observer.OnAttempt(attempt)
+		}
+		transport, lastErr = connect(d.factory, d.info, d.options)
+		if lastErr == nil {
+			return
+		}
+		if observer != nil {
+			observer.OnAttemptFailed(lastErr)
+		}
+		if fatal(lastErr) {
+			return nil, lastErr
 		}
-		break
 	}
-	err = checkServerInfo(d.options.ServerInfoReceiveDuration, transport, d.info)
+}
+
+// connect creates a new Transport and performs the handshake. If the server
+// redirects the client and the factory is a RedirectTransportFactory,
+// connect follows up to MaxRedirects redirects. If the handshake fails, the
+// created Transport is closed and, if the factory is a
+// FailureTrackingTransportFactory, the endpoint is marked as failed.
+func connect[T any](factory TransportFactory[T], info delegate.ServerInfo,
+	options Options,
+) (transport Transport[T], err error) {
+	transport, err = factory.New()
 	if err != nil {
-		if err == ErrServerInfoMismatch {
+		return
+	}
+	defer func() {
+		if err != nil {
+			if transport != nil {
+				transport.Close()
+				transport = nil
+			}
+			if tracker, ok := factory.(FailureTrackingTransportFactory[T]); ok {
+				tracker.MarkFailed()
+			}
+		}
+	}()
+	for redirects := 0; ; redirects++ {
+		err = handshake(transport, info, options)
+		var redirectErr RedirectError
+		if !errors.As(err, &redirectErr) || options.MaxRedirects == 0 {
+			return
+		}
+		rfactory, ok := factory.(RedirectTransportFactory[T])
+		if !ok {
+			return
+		}
+		if redirects >= options.MaxRedirects {
+			err = fmt.Errorf("%w, last one to %v", ErrTooManyRedirects,
+				redirectErr.Addr)
+			return
+		}
+		// The server closes the connection after the redirect anyway.
+		transport.Close()
+		if transport, err = rfactory.NewTo(redirectErr.Addr); err != nil {
 			return
 		}
-		goto Start
 	}
-	d.setTransport(transport)
-	return
+}
+
+// fatal reports whether err makes further reconnect attempts pointless.
+func fatal(err error) bool {
+	if errors.Is(err, ErrServerInfoMismatch) {
+		return true
+	}
+	var rejectedErr RejectedError
+	if errors.As(err, &rejectedErr) {
+		return !rejectedErr.Retryable()
+	}
+	return false
 }
 
 func (d ReconnectDelegate[T]) setTransport(transport Transport[T]) {
@@ -137,3 +262,18 @@ func (d ReconnectDelegate[T]) Transport() Transport[T] {
 func (d ReconnectDelegate[T]) closed() bool {
 	return !atomic.CompareAndSwapUint32(d.closedFlag, 0, 0)
 }
+
+// sleep waits for the delay d. It returns early if the context is done or
+// the delegate is closed.
+func sleep(ctx context.Context, done <-chan struct{}, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+		return cln.ErrClosed
+	case <-timer.C:
+		return nil
+	}
+}
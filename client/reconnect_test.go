@@ -1,6 +1,7 @@
 package client_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -48,7 +49,10 @@ func TestReconnectDelegate(t *testing.T) {
 				transport = clnmock.NewTransport().RegisterSetReceiveDeadline(
 					func(deadline time.Time) (err error) {
 						return wantErr
-					})
+					},
+				).RegisterClose(
+					func() (err error) { return nil },
+				)
 				factory = clnmock.NewTransportFactory().RegisterNew(
 					func() (dcln.Transport[any], error) {
 						return transport, nil
@@ -61,6 +65,80 @@ func TestReconnectDelegate(t *testing.T) {
 			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
 		})
 
+	t.Run("If the server redirects the client, NewReconnect should follow the redirect",
+		func(t *testing.T) {
+			var (
+				wantAddr  = "127.0.0.1:9001"
+				transport = makeRedirectTransport(wantAddr).RegisterClose(
+					func() (err error) { return nil },
+				)
+				redirected = makeClientTransport(serverInfo).RegisterReceiveAdmission(
+					func() (reason delegate.RejectReason, err error) {
+						return delegate.RejectNone, nil
+					},
+				)
+				factory = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return transport, nil },
+				).RegisterNewTo(
+					func(addr string) (dcln.Transport[any], error) {
+						asserterror.Equal(t, addr, wantAddr)
+						return redirected, nil
+					},
+				)
+				mocks = []*mok.Mock{transport.Mock, redirected.Mock, factory.Mock}
+			)
+			_, err := dcln.NewReconnect(serverInfo, factory,
+				append(ops, dcln.WithAdmission(), dcln.WithMaxRedirects(1))...)
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If the server redirects the client more than MaxRedirects times, NewReconnect should return ErrTooManyRedirects",
+		func(t *testing.T) {
+			var (
+				addr    = "127.0.0.1:9001"
+				wantErr = fmt.Errorf("%w, last one to %v",
+					dcln.ErrTooManyRedirects, addr)
+				transport = makeRedirectTransport(addr).RegisterClose(
+					func() (err error) { return nil },
+				)
+				redirected = makeRedirectTransport(addr).RegisterClose(
+					func() (err error) { return nil },
+				)
+				factory = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return transport, nil },
+				).RegisterNewTo(
+					func(addr string) (dcln.Transport[any], error) {
+						return redirected, nil
+					},
+				)
+				mocks = []*mok.Mock{transport.Mock, redirected.Mock, factory.Mock}
+			)
+			_, err := dcln.NewReconnect(serverInfo, factory,
+				append(ops, dcln.WithAdmission(), dcln.WithMaxRedirects(1))...)
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If MaxRedirects is 0, NewReconnect should return RedirectError",
+		func(t *testing.T) {
+			var (
+				wantAddr  = "127.0.0.1:9001"
+				wantErr   = dcln.NewRedirectError(wantAddr)
+				transport = makeRedirectTransport(wantAddr).RegisterClose(
+					func() (err error) { return nil },
+				)
+				factory = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return transport, nil },
+				)
+				mocks = []*mok.Mock{transport.Mock, factory.Mock}
+			)
+			_, err := dcln.NewReconnect(serverInfo, factory,
+				append(ops, dcln.WithAdmission())...)
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
 	t.Run("If ClientTransportFactory.New fails with an error, NewReconnect should return it",
 		func(t *testing.T) {
 			var (
@@ -274,7 +352,7 @@ func TestReconnectDelegate(t *testing.T) {
 		asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
 	})
 
-	t.Run("If Tranposrt.Close fails with an error, Close should return it",
+	t.Run("If Tranposrt.Close fails with an error, Close should return it and the delegate should still be closed",
 		func(t *testing.T) {
 			var (
 				wantErr = errors.New("Close error")
@@ -283,16 +361,24 @@ func TestReconnectDelegate(t *testing.T) {
 						return wantErr
 					},
 				)
-				tran = &atomic.Value{}
+				closeFlag uint32
+				tran      = &atomic.Value{}
 			)
 			tran.Store(clnTran)
 			var (
 				mocks    = []*mok.Mock{clnTran.Mock}
-				delegate = dcln.NewReconnectWithoutInfo[any](nil, nil, tran,
+				delegate = dcln.NewReconnectWithoutInfo[any](nil, &closeFlag, tran,
 					dcln.Options{})
 			)
 			err := delegate.Close()
 			asserterror.EqualError(t, err, wantErr)
+			asserterror.Equal(t, closeFlag, 1)
+
+			err = delegate.Reconnect()
+			asserterror.EqualError(t, err, ccln.ErrClosed)
+
+			err = delegate.Close()
+			asserterror.EqualError(t, err, nil)
 			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
 		})
 
@@ -378,9 +464,11 @@ func TestReconnectDelegate(t *testing.T) {
 						return nil
 					},
 				).RegisterReceiveServerInfo(
-					func() (info delegate.ServerInfo, err error) {
+					func(maxSize int) (info delegate.ServerInfo, err error) {
 						return []byte("different info"), nil
 					},
+				).RegisterClose(
+					func() (err error) { return nil },
 				)
 				tran = &atomic.Value{}
 			)
@@ -401,6 +489,60 @@ func TestReconnectDelegate(t *testing.T) {
 			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
 		})
 
+	t.Run("If the server rejects the connection as forbidden, Reconnect should return RejectedError",
+		func(t *testing.T) {
+			var (
+				wantErr = dcln.NewRejectedError(delegate.RejectForbidden)
+				clnTran = clnmock.NewTransport().RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterReceiveAdmission(
+					func() (reason delegate.RejectReason, err error) {
+						return delegate.RejectForbidden, nil
+					},
+				).RegisterClose(
+					func() (err error) { return nil },
+				)
+				factory = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return clnTran, nil },
+				)
+				closeFlag uint32
+				mocks     = []*mok.Mock{clnTran.Mock, factory.Mock}
+				delegate  = dcln.NewReconnectWithoutInfo(factory, &closeFlag,
+					&atomic.Value{}, dcln.Options{Admission: true})
+			)
+			err := delegate.Reconnect()
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If the server rejects the connection as busy, Reconnect should try again",
+		func(t *testing.T) {
+			var (
+				wantErr = dcln.NewReconnectError(2, dcln.ErrMaxReconnectAttempts,
+					dcln.NewRejectedError(delegate.RejectBusy))
+				clnTran = clnmock.NewTransport().RegisterNSetReceiveDeadline(2,
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterNReceiveAdmission(2,
+					func() (reason delegate.RejectReason, err error) {
+						return delegate.RejectBusy, nil
+					},
+				).RegisterNClose(2,
+					func() (err error) { return nil },
+				)
+				factory = clnmock.NewTransportFactory().RegisterNNew(2,
+					func() (dcln.Transport[any], error) { return clnTran, nil },
+				)
+				closeFlag uint32
+				mocks     = []*mok.Mock{clnTran.Mock, factory.Mock}
+				delegate  = dcln.NewReconnectWithoutInfo(factory, &closeFlag,
+					&atomic.Value{},
+					dcln.Options{Admission: true, MaxReconnectAttempts: 2})
+			)
+			err := delegate.Reconnect()
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
 	t.Run("If ServerInfo check fails with an error, Reconnect should try again",
 		func(t *testing.T) {
 			var (
@@ -411,6 +553,8 @@ func TestReconnectDelegate(t *testing.T) {
 						closeFlag = 1
 						return errors.New("SetReceiveDeadline error")
 					},
+				).RegisterClose(
+					func() (err error) { return nil },
 				)
 				tran = &atomic.Value{}
 			)
@@ -429,4 +573,407 @@ func TestReconnectDelegate(t *testing.T) {
 			asserterror.EqualError(t, err, wantErr)
 			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
 		})
+
+	t.Run("Reconnect should wait for Backoff delay between failed attempts",
+		func(t *testing.T) {
+			var (
+				delay         = 100 * time.Millisecond
+				start         = time.Now()
+				wantTransport = makeClientTransport(nil)
+				factory       = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) {
+						return nil, errors.New("transport creation error")
+					},
+				).RegisterNew(
+					func() (dcln.Transport[any], error) {
+						return clnmock.NewTransport().RegisterSetReceiveDeadline(
+							func(deadline time.Time) (err error) {
+								return errors.New("SetReceiveDeadline error")
+							},
+						).RegisterClose(
+							func() (err error) { return nil },
+						), nil
+					},
+				).RegisterNew(
+					func() (dcln.Transport[any], error) {
+						return wantTransport, nil
+					},
+				)
+				backoff = clnmock.NewBackoff().RegisterNext(
+					func(attempt int, prev time.Duration) time.Duration {
+						asserterror.Equal(t, attempt, 1)
+						asserterror.Equal(t, prev, 0)
+						return delay
+					},
+				).RegisterNext(
+					func(attempt int, prev time.Duration) time.Duration {
+						asserterror.Equal(t, attempt, 2)
+						asserterror.Equal(t, prev, delay)
+						return delay
+					},
+				)
+				closeFlag uint32
+				tran      = &atomic.Value{}
+				mocks     = []*mok.Mock{wantTransport.Mock, factory.Mock,
+					backoff.Mock}
+				delegate = dcln.NewReconnectWithoutInfo(factory, &closeFlag, tran,
+					dcln.Options{Backoff: backoff})
+			)
+			err := delegate.Reconnect()
+			asserterror.EqualError(t, err, nil)
+			asserterror.SameTime(t, time.Now(), start.Add(2*delay), 50*time.Millisecond)
+			if transport := delegate.Transport(); transport != wantTransport {
+				t.Errorf("unexpected transport, want '%v' actual '%v'", wantTransport,
+					transport)
+			}
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If MaxReconnectAttempts is reached, Reconnect should return ReconnectError",
+		func(t *testing.T) {
+			var (
+				dialErr = errors.New("transport creation error")
+				wantErr = dcln.NewReconnectError(2, dcln.ErrMaxReconnectAttempts,
+					dialErr)
+				factory = clnmock.NewTransportFactory().RegisterNNew(2,
+					func() (dcln.Transport[any], error) {
+						return nil, dialErr
+					},
+				)
+				closeFlag uint32
+				mocks     = []*mok.Mock{factory.Mock}
+				delegate  = dcln.NewReconnectWithoutInfo(factory, &closeFlag,
+					&atomic.Value{}, dcln.Options{MaxReconnectAttempts: 2})
+			)
+			err := delegate.Reconnect()
+			asserterror.EqualError(t, err, wantErr)
+			if !errors.Is(err, dcln.ErrMaxReconnectAttempts) || !errors.Is(err, dialErr) {
+				t.Errorf("unexpected error %v", err)
+			}
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If ReconnectDuration expires, Reconnect should return ReconnectError",
+		func(t *testing.T) {
+			var (
+				dialErr = errors.New("transport creation error")
+				wantErr = dcln.NewReconnectError(1, context.DeadlineExceeded,
+					dialErr)
+				factory = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) {
+						return nil, dialErr
+					},
+				)
+				closeFlag uint32
+				mocks     = []*mok.Mock{factory.Mock}
+				delegate  = dcln.NewReconnectWithoutInfo(factory, &closeFlag,
+					&atomic.Value{}, dcln.Options{
+						Backoff:           dcln.NewConstantBackoff(time.Second),
+						ReconnectDuration: 100 * time.Millisecond,
+					})
+			)
+			err := delegate.Reconnect()
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("Close should interrupt the Backoff delay and Reconnect should return ErrClosed",
+		func(t *testing.T) {
+			var (
+				wantErr = ccln.ErrClosed
+				clnTran = clnmock.NewTransport().RegisterClose(
+					func() (err error) { return nil },
+				)
+				factory = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) {
+						return nil, errors.New("transport creation error")
+					},
+				)
+				closeFlag uint32
+				tran      = &atomic.Value{}
+				mocks     = []*mok.Mock{clnTran.Mock, factory.Mock}
+				errs      = make(chan error, 1)
+			)
+			tran.Store(clnTran)
+			delegate := dcln.NewReconnectWithoutInfo(factory, &closeFlag, tran,
+				dcln.Options{Backoff: dcln.NewConstantBackoff(10 * time.Second)})
+			go func() { errs <- delegate.Reconnect() }()
+			time.Sleep(100 * time.Millisecond)
+
+			err := delegate.Close()
+			asserterror.EqualError(t, err, nil)
+			select {
+			case err = <-errs:
+				asserterror.EqualError(t, err, wantErr)
+			case <-time.NewTimer(time.Second).C:
+				t.Fatal("test lasts too long")
+			}
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If the context is canceled, ReconnectContext should return ReconnectError",
+		func(t *testing.T) {
+			var (
+				wantErr   = dcln.NewReconnectError(0, context.Canceled, nil)
+				closeFlag uint32
+				delegate  = dcln.NewReconnectWithoutInfo[any](nil, &closeFlag,
+					&atomic.Value{}, dcln.Options{})
+				ctx, cancel = context.WithCancel(context.Background())
+			)
+			cancel()
+			err := delegate.ReconnectContext(ctx)
+			asserterror.EqualError(t, err, wantErr)
+			var reconnectErr dcln.ReconnectError
+			if !errors.As(err, &reconnectErr) || reconnectErr.Attempts != 0 {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+
+	t.Run("Reconnect should notify ReconnectObserver", func(t *testing.T) {
+		var (
+			dialErr       = errors.New("transport creation error")
+			wantLocalAddr = &net.IPAddr{IP: net.ParseIP("127.0.0.1")}
+			wantRemoteAdd = &net.IPAddr{IP: net.ParseIP("127.0.0.2")}
+			wantTransport = makeClientTransport(nil).RegisterLocalAddr(
+				func() (addr net.Addr) { return wantLocalAddr },
+			).RegisterRemoteAddr(
+				func() (addr net.Addr) { return wantRemoteAdd },
+			)
+			factory = clnmock.NewTransportFactory().RegisterNew(
+				func() (dcln.Transport[any], error) { return nil, dialErr },
+			).RegisterNew(
+				func() (dcln.Transport[any], error) { return wantTransport, nil },
+			)
+			observer = clnmock.NewReconnectObserver().RegisterOnDisconnect(
+				func() {},
+			).RegisterOnAttempt(
+				func(attempt int) { asserterror.Equal(t, attempt, 1) },
+			).RegisterOnAttemptFailed(
+				func(err error) { asserterror.EqualError(t, err, dialErr) },
+			).RegisterOnAttempt(
+				func(attempt int) { asserterror.Equal(t, attempt, 2) },
+			).RegisterOnReconnected(
+				func(localAddr, remoteAddr net.Addr) {
+					asserterror.Equal[net.Addr](t, localAddr, wantLocalAddr)
+					asserterror.Equal[net.Addr](t, remoteAddr, wantRemoteAdd)
+				},
+			)
+			closeFlag uint32
+			mocks     = []*mok.Mock{wantTransport.Mock, factory.Mock,
+				observer.Mock}
+			delegate = dcln.NewReconnectWithoutInfo(factory, &closeFlag,
+				&atomic.Value{}, dcln.Options{ReconnectObserver: observer})
+		)
+		err := delegate.Reconnect()
+		asserterror.EqualError(t, err, nil)
+		asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+	})
+
+	t.Run("If Reconnect gives up, it should notify ReconnectObserver",
+		func(t *testing.T) {
+			var (
+				dialErr = errors.New("transport creation error")
+				wantErr = dcln.NewReconnectError(1, dcln.ErrMaxReconnectAttempts,
+					dialErr)
+				factory = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return nil, dialErr },
+				)
+				observer = clnmock.NewReconnectObserver().RegisterOnDisconnect(
+					func() {},
+				).RegisterOnAttempt(
+					func(attempt int) {},
+				).RegisterOnAttemptFailed(
+					func(err error) { asserterror.EqualError(t, err, dialErr) },
+				).RegisterOnGiveUp(
+					func(err error) { asserterror.EqualError(t, err, wantErr) },
+				)
+				closeFlag uint32
+				mocks     = []*mok.Mock{factory.Mock, observer.Mock}
+				delegate  = dcln.NewReconnectWithoutInfo(factory, &closeFlag,
+					&atomic.Value{}, dcln.Options{
+						MaxReconnectAttempts: 1,
+						ReconnectObserver:    observer,
+					})
+			)
+			err := delegate.Reconnect()
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If ServerInfoMatcher returns an error wrapping ErrServerInfoMismatch, Reconnect should return it",
+		func(t *testing.T) {
+			var (
+				wantErr = fmt.Errorf("%w: custom", dcln.ErrServerInfoMismatch)
+				matcher = clnmock.NewServerInfoMatcher().RegisterMatch(
+					func(want, got delegate.ServerInfo) (err error) { return wantErr },
+				)
+				transport = clnmock.NewTransport().RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterReceiveServerInfo(
+					func(maxSize int) (i delegate.ServerInfo, err error) { return nil, nil },
+				).RegisterClose(
+					func() (err error) { return nil },
+				)
+				factory = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) { return transport, nil },
+				)
+				closeFlag uint32
+				mocks     = []*mok.Mock{transport.Mock, factory.Mock, matcher.Mock}
+				delegate  = dcln.NewReconnectWithoutInfo(factory, &closeFlag,
+					&atomic.Value{}, dcln.Options{ServerInfoMatcher: matcher})
+			)
+			err := delegate.Reconnect()
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If ReconnectKeepaliveDelegate detects a dead peer, it should close only the current Transport and the client should reconnect",
+		func(t *testing.T) {
+			var (
+				closed      = make(chan struct{})
+				reconnected = make(chan struct{})
+				wantClosed  = make(chan struct{})
+				clnTran     = clnmock.NewTransport().RegisterSetSendDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterSend(
+					func(seq core.Seq, cmd core.Cmd[any]) (n int, err error) {
+						return 1, nil
+					},
+				).RegisterFlush(
+					func() (err error) { return nil },
+				).RegisterClose(
+					func() (err error) { defer close(closed); return nil },
+				).RegisterReceive(
+					func() (seq core.Seq, result core.Result, n int, err error) {
+						<-closed
+						return 0, nil, 0, errors.New("use of closed connection")
+					},
+				)
+				wantTransport = makeClientTransport(nil).RegisterReceive(
+					func() (seq core.Seq, result core.Result, n int, err error) {
+						<-wantClosed
+						return 0, nil, 0, errors.New("use of closed connection")
+					},
+				).RegisterClose(
+					func() (err error) { defer close(wantClosed); return nil },
+				)
+				factory = clnmock.NewTransportFactory().RegisterNew(
+					func() (dcln.Transport[any], error) {
+						defer close(reconnected)
+						return wantTransport, nil
+					},
+				)
+				closeFlag uint32
+				tran      = &atomic.Value{}
+				mocks     = []*mok.Mock{clnTran.Mock, wantTransport.Mock,
+					factory.Mock}
+			)
+			tran.Store(clnTran)
+			var (
+				reconnect = dcln.NewReconnectWithoutInfo[any](factory, &closeFlag,
+					tran, dcln.Options{})
+				dlgt = dcln.NewReconnectKeepalive[any](reconnect,
+					dcln.WithKeepaliveTime(100*time.Millisecond),
+					dcln.WithKeepaliveIntvl(300*time.Millisecond),
+					dcln.WithKeepaliveCnt(1),
+				)
+				client = ccln.New[any](dlgt)
+			)
+
+			select {
+			case <-reconnected:
+			case <-time.NewTimer(time.Second).C:
+				t.Fatal("test lasts too long")
+			}
+			select {
+			case <-client.Done():
+				t.Fatalf("client was closed, err '%v'", client.Err())
+			default:
+			}
+			asserterror.Equal(t, closeFlag, 0)
+
+			err := client.Close()
+			asserterror.EqualError(t, err, nil)
+			<-client.Done()
+			asserterror.Equal(t, closeFlag, 1)
+			if transport := reconnect.Transport(); transport != wantTransport {
+				t.Errorf("unexpected transport, want '%v' actual '%v'", wantTransport,
+					transport)
+			}
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If ReconnectDelegate is closed after a keepalive timeout, Reconnect should stop",
+		func(t *testing.T) {
+			var (
+				closed  = make(chan struct{})
+				clnTran = clnmock.NewTransport().RegisterSetSendDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterSend(
+					func(seq core.Seq, cmd core.Cmd[any]) (n int, err error) {
+						return 1, nil
+					},
+				).RegisterFlush(
+					func() (err error) { return nil },
+				).RegisterClose(
+					func() (err error) { defer close(closed); return nil },
+				).RegisterReceive(
+					func() (seq core.Seq, result core.Result, n int, err error) {
+						<-closed
+						return 0, nil, 0, errors.New("use of closed connection")
+					},
+				).RegisterClose(
+					func() (err error) { return errors.New("use of closed connection") },
+				)
+				factory = clnmock.NewTransportFactory().RegisterNNew(1000,
+					func() (dcln.Transport[any], error) {
+						return nil, errors.New("connection refused")
+					},
+				)
+				closeFlag uint32
+				tran      = &atomic.Value{}
+			)
+			tran.Store(clnTran)
+			var (
+				reconnect = dcln.NewReconnectWithoutInfo[any](factory, &closeFlag,
+					tran, dcln.Options{
+						Backoff: dcln.NewConstantBackoff(10 * time.Millisecond),
+					})
+				dlgt = dcln.NewReconnectKeepalive[any](reconnect,
+					dcln.WithKeepaliveTime(100*time.Millisecond),
+					dcln.WithKeepaliveIntvl(300*time.Millisecond),
+					dcln.WithKeepaliveCnt(1),
+				)
+				client = ccln.New[any](dlgt)
+			)
+
+			select {
+			case <-closed:
+			case <-time.NewTimer(time.Second).C:
+				t.Fatal("test lasts too long")
+			}
+			err := client.Close()
+			asserterror.EqualError(t, err, nil)
+			select {
+			case <-client.Done():
+			case <-time.NewTimer(time.Second).C:
+				t.Fatal("Reconnect was not stopped")
+			}
+			asserterror.Equal(t, closeFlag, 1)
+			asserterror.EqualError(t, client.Err(), ccln.ErrClosed)
+		})
+}
+
+func makeRedirectTransport(addr string) clnmock.Transport {
+	return clnmock.NewTransport().RegisterSetReceiveDeadline(
+		func(deadline time.Time) (err error) { return nil },
+	).RegisterReceiveAdmission(
+		func() (reason delegate.RejectReason, err error) {
+			return delegate.RejectRedirect, nil
+		},
+	).RegisterReceiveRedirect(
+		func() (r delegate.Redirect, err error) {
+			return delegate.Redirect{Addr: addr}, nil
+		},
+	)
 }
@@ -10,10 +10,40 @@ type TransportFactory[T any] interface {
 	New() (Transport[T], error)
 }
 
+// RedirectTransportFactory is a TransportFactory, which can also create a
+// Transport to the address received from the server in delegate.Redirect.
+// ReconnectDelegate follows redirects only with such a factory.
+type RedirectTransportFactory[T any] interface {
+	TransportFactory[T]
+	NewTo(addr string) (Transport[T], error)
+}
+
+// FailureTrackingTransportFactory is a TransportFactory, which tracks the
+// health of its endpoints. ReconnectDelegate calls MarkFailed when the
+// handshake over the last created Transport fails, for example, because the
+// server is busy, so the endpoint can be avoided like one that can't be
+// dialed.
+type FailureTrackingTransportFactory[T any] interface {
+	TransportFactory[T]
+	MarkFailed()
+}
+
 // Transport is a transport for the client delegate.
 //
 // It is used by the delegate to send Commands and receive Results.
 type Transport[T any] interface {
 	delegate.Transport[core.Cmd[T], core.Result]
-	ReceiveServerInfo() (info delegate.ServerInfo, err error)
+	ReceivePreamble() (p delegate.Preamble, err error)
+	ReceiveAdmission() (reason delegate.RejectReason, err error)
+	ReceiveRedirect() (r delegate.Redirect, err error)
+	// ReceiveServerInfo receives ServerInfo. If maxSize != 0, a bigger
+	// ServerInfo must cause delegate.ErrServerInfoTooLarge before memory for
+	// it is allocated, for example, by decoding it with
+	// delegate.NewBoundedServerInfoMUS.
+	ReceiveServerInfo(maxSize int) (info delegate.ServerInfo, err error)
+	ReceiveAuthChallenge() (c delegate.AuthChallenge, err error)
+	// SendAuthResponse sends and flushes AuthResponse.
+	SendAuthResponse(r delegate.AuthResponse) (err error)
+	// SendClientInfo sends and flushes ClientInfo.
+	SendClientInfo(info delegate.ClientInfo) (err error)
 }
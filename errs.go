@@ -0,0 +1,31 @@
+package delegate
+
+import "errors"
+
+// ErrServerInfoTooLarge happens when the received ServerInfo exceeds the
+// configured maximum size.
+var ErrServerInfoTooLarge = errors.New("server info too large")
+
+// ErrRedirectAddrTooLong happens when the received Redirect address exceeds
+// MaxRedirectAddrSize.
+var ErrRedirectAddrTooLong = errors.New("redirect address too long")
+
+// ErrAuthKeyIDTooLong happens when the received AuthResponse key ID exceeds
+// MaxAuthKeyIDSize.
+var ErrAuthKeyIDTooLong = errors.New("auth key ID too long")
+
+// ErrInvalidMACSize happens when the received AuthResponse MAC is not a
+// SHA-256 HMAC.
+var ErrInvalidMACSize = errors.New("invalid MAC size")
+
+// ErrClientInfoTooLarge happens when the received ClientInfo exceeds the
+// configured maximum size.
+var ErrClientInfoTooLarge = errors.New("client info too large")
+
+// ErrInvalidNonceSize happens when the received AuthChallenge nonce is not
+// NonceSize bytes long.
+var ErrInvalidNonceSize = errors.New("invalid nonce size")
+
+// ErrVersionedInfoTooLarge happens when a length in the received
+// VersionedInfo exceeds the allowed size.
+var ErrVersionedInfoTooLarge = errors.New("versioned info too large")
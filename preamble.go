@@ -0,0 +1,21 @@
+package delegate
+
+// HandshakeVersion is the version of the handshake framing, which follows
+// the Preamble.
+const HandshakeVersion byte = 1
+
+// Magic identifies a cmd-stream peer.
+var Magic = [4]byte{'C', 'M', 'D', 'S'}
+
+// Preamble is sent by the server before ServerInfo, so that the client can
+// quickly detect that it is connected to something other than a cmd-stream
+// server, and to allow the handshake format to evolve.
+type Preamble struct {
+	Magic   [4]byte
+	Version byte
+}
+
+// NewPreamble creates a Preamble with Magic and the current HandshakeVersion.
+func NewPreamble() Preamble {
+	return Preamble{Magic: Magic, Version: HandshakeVersion}
+}
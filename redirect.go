@@ -0,0 +1,10 @@
+package delegate
+
+// MaxRedirectAddrSize is the maximum size of Redirect.Addr.
+const MaxRedirectAddrSize = 255
+
+// Redirect is sent by the server after the RejectRedirect admission frame and
+// tells the client to connect to Addr instead.
+type Redirect struct {
+	Addr string
+}
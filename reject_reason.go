@@ -0,0 +1,33 @@
+package delegate
+
+import "strconv"
+
+// RejectReason is sent by the server in the admission frame. RejectNone
+// means the connection is admitted, any other value means it is rejected and
+// will be closed. RejectRedirect is followed by Redirect.
+type RejectReason byte
+
+const (
+	RejectNone RejectReason = iota
+	RejectBusy
+	RejectShuttingDown
+	RejectForbidden
+	RejectRedirect
+)
+
+func (r RejectReason) String() string {
+	switch r {
+	case RejectNone:
+		return "none"
+	case RejectBusy:
+		return "busy"
+	case RejectShuttingDown:
+		return "shutting down"
+	case RejectForbidden:
+		return "forbidden"
+	case RejectRedirect:
+		return "redirect"
+	default:
+		return "unknown(" + strconv.Itoa(int(r)) + ")"
+	}
+}
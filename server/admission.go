@@ -0,0 +1,130 @@
+package server
+
+import (
+	"net"
+	"sync"
+
+	"github.com/cmd-stream/delegate-go"
+)
+
+// Admission decides whether a new connection may proceed with the handshake.
+//
+// Admit returns delegate.RejectNone to accept the connection, or a reason to
+// reject it. Release is called for every accepted connection once it has
+// been handled.
+type Admission interface {
+	Admit(conn net.Conn) delegate.RejectReason
+	Release(conn net.Conn)
+}
+
+// AdmissionFunc allows a custom predicate to be used as an Admission.
+type AdmissionFunc func(conn net.Conn) delegate.RejectReason
+
+func (f AdmissionFunc) Admit(conn net.Conn) delegate.RejectReason {
+	return f(conn)
+}
+
+func (f AdmissionFunc) Release(conn net.Conn) {}
+
+// NewMaxConnsAdmission creates a new MaxConnsAdmission.
+func NewMaxConnsAdmission(max int) *MaxConnsAdmission {
+	return &MaxConnsAdmission{max: max}
+}
+
+// MaxConnsAdmission limits the number of concurrent connections. Connections
+// above the limit are rejected with delegate.RejectBusy.
+type MaxConnsAdmission struct {
+	mu    sync.Mutex
+	max   int
+	conns int
+}
+
+func (a *MaxConnsAdmission) Admit(conn net.Conn) delegate.RejectReason {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.conns >= a.max {
+		return delegate.RejectBusy
+	}
+	a.conns++
+	return delegate.RejectNone
+}
+
+func (a *MaxConnsAdmission) Release(conn net.Conn) {
+	a.mu.Lock()
+	a.conns--
+	a.mu.Unlock()
+}
+
+// NewPerIPAdmission creates a new PerIPAdmission.
+func NewPerIPAdmission(max int) *PerIPAdmission {
+	return &PerIPAdmission{max: max, conns: map[string]int{}}
+}
+
+// PerIPAdmission limits the number of concurrent connections from a single
+// IP address. Connections above the limit are rejected with
+// delegate.RejectBusy.
+type PerIPAdmission struct {
+	mu    sync.Mutex
+	max   int
+	conns map[string]int
+}
+
+func (a *PerIPAdmission) Admit(conn net.Conn) delegate.RejectReason {
+	ip := remoteIP(conn)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.conns[ip] >= a.max {
+		return delegate.RejectBusy
+	}
+	a.conns[ip]++
+	return delegate.RejectNone
+}
+
+func (a *PerIPAdmission) Release(conn net.Conn) {
+	ip := remoteIP(conn)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.conns[ip]--; a.conns[ip] <= 0 {
+		delete(a.conns, ip)
+	}
+}
+
+// NewAdmissionChain creates a new AdmissionChain.
+func NewAdmissionChain(admissions ...Admission) AdmissionChain {
+	return AdmissionChain(admissions)
+}
+
+// AdmissionChain accepts a connection only if all of its Admissions accept
+// it. Admissions are consulted in order, the first rejection reason is
+// returned.
+type AdmissionChain []Admission
+
+func (c AdmissionChain) Admit(conn net.Conn) (reason delegate.RejectReason) {
+	for i, a := range c {
+		if reason = a.Admit(conn); reason != delegate.RejectNone {
+			for j := i - 1; j >= 0; j-- {
+				c[j].Release(conn)
+			}
+			return
+		}
+	}
+	return
+}
+
+func (c AdmissionChain) Release(conn net.Conn) {
+	for i := len(c) - 1; i >= 0; i-- {
+		c[i].Release(conn)
+	}
+}
+
+func remoteIP(conn net.Conn) string {
+	addr := conn.RemoteAddr()
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+		return tcpAddr.IP.String()
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
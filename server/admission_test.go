@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/cmd-stream/delegate-go"
+	asserterror "github.com/ymz-ncnk/assert/error"
+)
+
+func TestMaxConnsAdmission(t *testing.T) {
+	var (
+		a    = NewMaxConnsAdmission(1)
+		conn = addrConn{addr: tcpAddr("127.0.0.1")}
+	)
+	asserterror.Equal(t, a.Admit(conn), delegate.RejectNone)
+	asserterror.Equal(t, a.Admit(conn), delegate.RejectBusy)
+	a.Release(conn)
+	asserterror.Equal(t, a.Admit(conn), delegate.RejectNone)
+}
+
+func TestPerIPAdmission(t *testing.T) {
+	var (
+		a     = NewPerIPAdmission(1)
+		conn1 = addrConn{addr: tcpAddr("127.0.0.1")}
+		conn2 = addrConn{addr: tcpAddr("127.0.0.2")}
+	)
+	asserterror.Equal(t, a.Admit(conn1), delegate.RejectNone)
+	asserterror.Equal(t, a.Admit(conn1), delegate.RejectBusy)
+	asserterror.Equal(t, a.Admit(conn2), delegate.RejectNone)
+	a.Release(conn1)
+	asserterror.Equal(t, a.Admit(conn1), delegate.RejectNone)
+}
+
+func TestAdmissionChain(t *testing.T) {
+	var (
+		maxConns = NewMaxConnsAdmission(1)
+		chain    = NewAdmissionChain(maxConns,
+			AdmissionFunc(func(conn net.Conn) delegate.RejectReason {
+				return delegate.RejectForbidden
+			}))
+		conn = addrConn{addr: tcpAddr("127.0.0.1")}
+	)
+	asserterror.Equal(t, chain.Admit(conn), delegate.RejectForbidden)
+	// MaxConnsAdmission should be released after the rejection.
+	asserterror.Equal(t, maxConns.Admit(conn), delegate.RejectNone)
+}
+
+type addrConn struct {
+	net.Conn
+	addr net.Addr
+}
+
+func (c addrConn) RemoteAddr() net.Addr { return c.addr }
+
+func tcpAddr(ip string) *net.TCPAddr {
+	return &net.TCPAddr{IP: net.ParseIP(ip), Port: 9000}
+}
@@ -0,0 +1,22 @@
+package server
+
+import (
+	"context"
+
+	"github.com/cmd-stream/delegate-go"
+)
+
+// ClientInfoValidator checks ClientInfo received from the client. If it
+// returns an error, the connection is closed.
+type ClientInfoValidator interface {
+	Validate(info delegate.ClientInfo) error
+}
+
+type clientInfoKey struct{}
+
+// ClientInfoFrom returns ClientInfo received from the client during the
+// handshake, if any.
+func ClientInfoFrom(ctx context.Context) (info delegate.ClientInfo, ok bool) {
+	info, ok = ctx.Value(clientInfoKey{}).(delegate.ClientInfo)
+	return
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/cmd-stream/delegate-go"
+)
+
+var lastConnID atomic.Uint64
+
+// ConnInfo describes a connection that has completed the handshake.
+//
+// ID is unique within the process, HandshakeTime is the time the handshake
+// was completed. ClientInfo is nil unless ClientInfoValidator is configured.
+type ConnInfo struct {
+	ID            uint64
+	LocalAddr     net.Addr
+	RemoteAddr    net.Addr
+	HandshakeTime time.Time
+	ServerInfo    delegate.ServerInfo
+	ClientInfo    delegate.ClientInfo
+}
+
+type connInfoKey struct{}
+
+// ConnInfoFrom returns ConnInfo of the connection, which is handled with
+// ctx.
+func ConnInfoFrom(ctx context.Context) (info ConnInfo, ok bool) {
+	info, ok = ctx.Value(connInfoKey{}).(ConnInfo)
+	return
+}
+
+func newConnInfo[T any](transport Transport[T], info delegate.ServerInfo,
+	clientInfo delegate.ClientInfo,
+) ConnInfo {
+	return ConnInfo{
+		ID:            lastConnID.Add(1),
+		LocalAddr:     transport.LocalAddr(),
+		RemoteAddr:    transport.RemoteAddr(),
+		HandshakeTime: time.Now(),
+		ServerInfo:    info,
+		ClientInfo:    clientInfo,
+	}
+}
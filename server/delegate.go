@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
 	"time"
 
@@ -10,13 +12,28 @@ import (
 
 // New creates a new Delegate.
 //
-// Panics with ErrEmptyInfo if ServerInfo is empty.
+// Panics with ErrEmptyInfo if ServerInfo is empty, use TryNew to get an error
+// instead.
 func New[T any](info delegate.ServerInfo, factory TransportFactory[T],
 	handler TransportHandler[T],
 	opts ...SetOption,
 ) (d Delegate[T]) {
+	d, err := TryNew(info, factory, handler, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// TryNew creates a new Delegate.
+//
+// Returns ErrEmptyInfo if ServerInfo is empty.
+func TryNew[T any](info delegate.ServerInfo, factory TransportFactory[T],
+	handler TransportHandler[T],
+	opts ...SetOption,
+) (d Delegate[T], err error) {
 	if len(info) == 0 {
-		panic(ErrEmptyInfo)
+		return d, ErrEmptyInfo
 	}
 	Apply(opts, &d.options)
 	d.info = info
@@ -27,7 +44,17 @@ func New[T any](info delegate.ServerInfo, factory TransportFactory[T],
 
 // Delegate implements the core.ServerDelegate interface.
 //
-// It initializes the connection by sending ServerInfo to the client.
+// It initializes the connection by sending ServerInfo to the client and,
+// optionally, authenticating the client and receiving ClientInfo from it. If
+// Admission is configured, a connection it rejects receives the rejection
+// reason instead of ServerInfo. Similarly, RedirectPolicy can send the client
+// to another server.
+//
+// If the handshake fails, Handle closes the Transport and returns
+// HandshakeError.
+//
+// After the handshake, the TransportHandler receives a context with
+// ConnInfo, available through ConnInfoFrom.
 type Delegate[T any] struct {
 	info    delegate.ServerInfo
 	factory TransportFactory[T]
@@ -36,23 +63,139 @@ type Delegate[T any] struct {
 }
 
 func (d Delegate[T]) Handle(ctx context.Context, conn net.Conn) (err error) {
+	var (
+		reason   = delegate.RejectNone
+		redirect delegate.Redirect
+		ok       bool
+	)
+	if d.options.RedirectPolicy != nil {
+		if redirect.Addr, ok = d.options.RedirectPolicy.Redirect(conn); ok {
+			reason = delegate.RejectRedirect
+		}
+	}
+	if reason == delegate.RejectNone && d.options.Admission != nil {
+		reason = d.options.Admission.Admit(conn)
+		if reason == delegate.RejectNone {
+			defer d.options.Admission.Release(conn)
+		}
+	}
 	transport := d.factory.New(conn)
-	err = d.sendServerInfo(transport)
+	ctx, phase, err := d.handshake(ctx, transport, reason, redirect)
 	if err != nil {
-		if err := transport.Close(); err != nil {
-			panic(err)
+		if closeErr := transport.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
 		}
-		return err
+		return NewHandshakeError(phase, err)
+	}
+	clientInfo, _ := ClientInfoFrom(ctx)
+	ctx = context.WithValue(ctx, connInfoKey{},
+		newConnInfo(transport, d.info, clientInfo))
+	if d.options.IdleTimeout != 0 {
+		transport = NewIdleTransport(transport, d.options.IdleTimeout)
 	}
 	return d.handler.Handle(ctx, transport)
 }
 
-func (d Delegate[T]) sendServerInfo(transport Transport[T]) (err error) {
+func (d Delegate[T]) handshake(ctx context.Context, transport Transport[T],
+	reason delegate.RejectReason,
+	redirect delegate.Redirect,
+) (_ context.Context, phase HandshakePhase, err error) {
+	phase = PhaseServerInfo
+	if err = d.sendServerInfo(transport, reason, redirect); err != nil {
+		return ctx, phase, err
+	}
+	if len(d.options.AuthKeys) == 0 && d.options.ClientInfoValidator == nil {
+		return ctx, phase, nil
+	}
+	if len(d.options.AuthKeys) != 0 {
+		phase = PhaseAuth
+	} else {
+		phase = PhaseClientInfo
+	}
+	if d.options.HandshakeReceiveDuration != 0 {
+		deadline := time.Now().Add(d.options.HandshakeReceiveDuration)
+		if err = transport.SetReceiveDeadline(deadline); err != nil {
+			return ctx, phase, err
+		}
+	}
+	if len(d.options.AuthKeys) != 0 {
+		if err = d.authenticate(transport); err != nil {
+			return ctx, phase, err
+		}
+	}
+	if d.options.ClientInfoValidator != nil {
+		phase = PhaseClientInfo
+		var info delegate.ClientInfo
+		if info, err = d.receiveClientInfo(transport); err != nil {
+			return ctx, phase, err
+		}
+		ctx = context.WithValue(ctx, clientInfoKey{}, info)
+	}
+	if d.options.HandshakeReceiveDuration != 0 {
+		err = transport.SetReceiveDeadline(time.Time{})
+	}
+	return ctx, phase, err
+}
+
+func (d Delegate[T]) sendServerInfo(transport Transport[T],
+	reason delegate.RejectReason,
+	redirect delegate.Redirect,
+) (err error) {
 	if d.options.ServerInfoSendDuration != 0 {
 		deadline := time.Now().Add(d.options.ServerInfoSendDuration)
 		if err = transport.SetSendDeadline(deadline); err != nil {
 			return
 		}
 	}
+	if d.options.Preamble {
+		if err = transport.SendPreamble(delegate.NewPreamble()); err != nil {
+			return
+		}
+	}
+	if d.options.Admission != nil || d.options.RedirectPolicy != nil {
+		if err = transport.SendAdmission(reason); err != nil {
+			return
+		}
+		switch reason {
+		case delegate.RejectNone:
+		case delegate.RejectRedirect:
+			if err = transport.SendRedirect(redirect); err != nil {
+				return
+			}
+			return fmt.Errorf("%w to %v", ErrRedirected, redirect.Addr)
+		default:
+			return fmt.Errorf("%w: %v", ErrRejected, reason)
+		}
+	}
 	return transport.SendServerInfo(d.info)
 }
+
+func (d Delegate[T]) authenticate(transport Transport[T]) (err error) {
+	challenge, err := delegate.NewAuthChallenge()
+	if err != nil {
+		return
+	}
+	if err = transport.SendAuthChallenge(challenge); err != nil {
+		return
+	}
+	resp, err := transport.ReceiveAuthResponse()
+	if err != nil {
+		return
+	}
+	for _, key := range d.options.AuthKeys {
+		if resp.Verify(key, challenge) {
+			return
+		}
+	}
+	return ErrAuthFailed
+}
+
+func (d Delegate[T]) receiveClientInfo(transport Transport[T]) (
+	info delegate.ClientInfo, err error,
+) {
+	if info, err = transport.ReceiveClientInfo(); err != nil {
+		return
+	}
+	err = d.options.ClientInfoValidator.Validate(info)
+	return
+}
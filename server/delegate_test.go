@@ -3,6 +3,7 @@ package server_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"reflect"
 	"testing"
@@ -40,7 +41,40 @@ func TestDelegate(t *testing.T) {
 			dsrv.New[any](nil, nil, nil, ops...)
 		})
 
-	t.Run("If send ServerInfo fails with an error, Handle should return it",
+	t.Run("If ServerInfo len is zero, TryNew should return ErrEmptyInfo",
+		func(t *testing.T) {
+			_, err := dsrv.TryNew[any](nil, nil, nil, ops...)
+			asserterror.EqualError(t, err, dsrv.ErrEmptyInfo)
+		})
+
+	t.Run("If Transport.Close fails after a handshake error, Handle should join both errors",
+		func(t *testing.T) {
+			var (
+				sendErr  = errors.New("send ServerInfo error")
+				closeErr = errors.New("close error")
+				wantErr  = dsrv.NewHandshakeError(dsrv.PhaseServerInfo,
+					errors.Join(sendErr, closeErr))
+				conn      = cmock.NewConn()
+				transport = srvmock.NewTransport().RegisterSetSendDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterSendServerInfo(
+					func(info delegate.ServerInfo) (err error) { return sendErr },
+				).RegisterClose(
+					func() (err error) { return closeErr },
+				)
+				factory  = makeTransportFactory(conn, transport, t)
+				delegate = dsrv.New(serverInfo, factory, nil, ops...)
+				mocks    = []*mok.Mock{conn.Mock, transport.Mock, factory.Mock}
+			)
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err, wantErr)
+			if !errors.Is(err, sendErr) || !errors.Is(err, closeErr) {
+				t.Errorf("unexpected error %v", err)
+			}
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If send ServerInfo fails with an error, Handle should return HandshakeError",
 		func(t *testing.T) {
 			var (
 				wantErr   = errors.New("send ServerInfo error")
@@ -57,7 +91,8 @@ func TestDelegate(t *testing.T) {
 				mocks    = []*mok.Mock{conn.Mock, transport.Mock, factory.Mock}
 			)
 			err := delegate.Handle(context.Background(), conn)
-			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualError(t, err,
+				dsrv.NewHandshakeError(dsrv.PhaseServerInfo, wantErr))
 			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
 		})
 
@@ -68,7 +103,7 @@ func TestDelegate(t *testing.T) {
 				conn      = cmock.NewConn()
 				transport = makeTransport(time.Now(), serverInfo,
 					wantServerInfoSendDuration, delta, t)
-				factory = makeTransportFactory(conn, transport, t)
+				factory = makeTransportFactory(conn, registerAddrs(transport), t)
 				handler = srvmock.NewTransportHandler().RegisterHandle(
 					func(ctx context.Context, transport dsrv.Transport[any]) error {
 						return wantErr
@@ -85,7 +120,71 @@ func TestDelegate(t *testing.T) {
 			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
 		})
 
-	t.Run("If Transport.SetSendDeadline fails with an error on ServerInfo send, Handle should return it",
+	t.Run("Handle should pass ConnInfo with a unique ID to the handler",
+		func(t *testing.T) {
+			var (
+				ids     []uint64
+				conn    = cmock.NewConn()
+				handler = srvmock.NewTransportHandler().RegisterNHandle(2,
+					func(ctx context.Context, transport dsrv.Transport[any]) error {
+						info, ok := dsrv.ConnInfoFrom(ctx)
+						asserterror.Equal(t, ok, true)
+						asserterror.Equal[net.Addr](t, info.LocalAddr, localAddr)
+						asserterror.Equal[net.Addr](t, info.RemoteAddr, remoteAddr)
+						asserterror.SameTime(t, info.HandshakeTime, time.Now(), delta)
+						asserterror.EqualDeep(t, info.ServerInfo, serverInfo)
+						ids = append(ids, info.ID)
+						return nil
+					},
+				)
+			)
+			for range 2 {
+				var (
+					transport = registerAddrs(makeTransport(time.Now(), serverInfo,
+						wantServerInfoSendDuration, delta, t))
+					factory  = makeTransportFactory(conn, transport, t)
+					delegate = dsrv.New(serverInfo, factory, handler, ops...)
+				)
+				err := delegate.Handle(context.Background(), conn)
+				asserterror.EqualError(t, err, nil)
+				asserterror.EqualDeep(t, mok.CheckCalls([]*mok.Mock{transport.Mock}),
+					mok.EmptyInfomap)
+			}
+			if len(ids) != 2 || ids[0] == ids[1] {
+				t.Errorf("unexpected connection IDs %v", ids)
+			}
+			asserterror.EqualDeep(t, mok.CheckCalls([]*mok.Mock{handler.Mock}),
+				mok.EmptyInfomap)
+		})
+
+	t.Run("If WithIdleTimeout is used, Handle should pass IdleTransport to the handler",
+		func(t *testing.T) {
+			var (
+				conn      = cmock.NewConn()
+				transport = registerAddrs(makeTransport(time.Now(), serverInfo,
+					wantServerInfoSendDuration, delta, t))
+				factory = makeTransportFactory(conn, transport, t)
+				handler = srvmock.NewTransportHandler().RegisterHandle(
+					func(ctx context.Context, transport dsrv.Transport[any]) error {
+						if _, ok := transport.(*dsrv.IdleTransport[any]); !ok {
+							t.Errorf("unexpected transport type %T", transport)
+						}
+						return nil
+					},
+				)
+				delegate = dsrv.New(serverInfo, factory, handler,
+					append(ops, dsrv.WithIdleTimeout(time.Minute))...)
+				mocks = []*mok.Mock{
+					conn.Mock, transport.Mock, factory.Mock,
+					handler.Mock,
+				}
+			)
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If Transport.SetSendDeadline fails with an error on ServerInfo send, Handle should return HandshakeError",
 		func(t *testing.T) {
 			var (
 				wantErr   = errors.New("SendServerInfo error")
@@ -99,7 +198,322 @@ func TestDelegate(t *testing.T) {
 				delegate = dsrv.New(serverInfo, factory, nil, ops...)
 				err      = delegate.Handle(context.Background(), conn)
 			)
-			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualError(t, err,
+				dsrv.NewHandshakeError(dsrv.PhaseServerInfo, wantErr))
+		})
+
+	t.Run("If ClientInfoValidator is set, Handle should receive ClientInfo and pass it to the handler",
+		func(t *testing.T) {
+			var (
+				wantInfo  = delegate.ClientInfo("client info")
+				conn      = cmock.NewConn()
+				transport = makeTransport(time.Now(), serverInfo,
+					wantServerInfoSendDuration, delta, t).RegisterNSetReceiveDeadline(2,
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterReceiveClientInfo(
+					func() (info delegate.ClientInfo, err error) { return wantInfo, nil },
+				)
+				factory   = makeTransportFactory(conn, registerAddrs(transport), t)
+				validator = srvmock.NewClientInfoValidator().RegisterValidate(
+					func(info delegate.ClientInfo) (err error) {
+						asserterror.EqualDeep(t, info, wantInfo)
+						return nil
+					},
+				)
+				handler = srvmock.NewTransportHandler().RegisterHandle(
+					func(ctx context.Context, transport dsrv.Transport[any]) error {
+						info, ok := dsrv.ClientInfoFrom(ctx)
+						asserterror.Equal(t, ok, true)
+						asserterror.EqualDeep(t, info, wantInfo)
+						connInfo, ok := dsrv.ConnInfoFrom(ctx)
+						asserterror.Equal(t, ok, true)
+						asserterror.EqualDeep(t, connInfo.ClientInfo, wantInfo)
+						return nil
+					},
+				)
+				delegate = dsrv.New(serverInfo, factory, handler,
+					append(ops, dsrv.WithHandshakeReceiveDuration(time.Second),
+						dsrv.WithClientInfoValidator(validator))...)
+				mocks = []*mok.Mock{
+					conn.Mock, transport.Mock, factory.Mock,
+					validator.Mock, handler.Mock,
+				}
+			)
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If ClientInfoValidator rejects ClientInfo, Handle should close the transport and return the error",
+		func(t *testing.T) {
+			var (
+				wantErr   = errors.New("forbidden")
+				conn      = cmock.NewConn()
+				transport = makeTransport(time.Now(), serverInfo,
+					wantServerInfoSendDuration, delta, t).RegisterReceiveClientInfo(
+					func() (info delegate.ClientInfo, err error) {
+						return delegate.ClientInfo("client info"), nil
+					},
+				).RegisterClose(
+					func() (err error) { return nil },
+				)
+				factory   = makeTransportFactory(conn, transport, t)
+				validator = srvmock.NewClientInfoValidator().RegisterValidate(
+					func(info delegate.ClientInfo) (err error) { return wantErr },
+				)
+				delegate = dsrv.New(serverInfo, factory, nil,
+					append(ops, dsrv.WithClientInfoValidator(validator))...)
+				mocks = []*mok.Mock{
+					conn.Mock, transport.Mock, factory.Mock,
+					validator.Mock,
+				}
+			)
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err,
+				dsrv.NewHandshakeError(dsrv.PhaseClientInfo, wantErr))
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If Transport.ReceiveClientInfo fails with an error, Handle should return HandshakeError",
+		func(t *testing.T) {
+			var (
+				wantErr   = errors.New("ReceiveClientInfo error")
+				conn      = cmock.NewConn()
+				transport = makeTransport(time.Now(), serverInfo,
+					wantServerInfoSendDuration, delta, t).RegisterReceiveClientInfo(
+					func() (info delegate.ClientInfo, err error) { return nil, wantErr },
+				).RegisterClose(
+					func() (err error) { return nil },
+				)
+				factory  = makeTransportFactory(conn, transport, t)
+				delegate = dsrv.New(serverInfo, factory, nil,
+					append(ops, dsrv.WithClientInfoValidator(
+						srvmock.NewClientInfoValidator()))...)
+				mocks = []*mok.Mock{conn.Mock, transport.Mock, factory.Mock}
+			)
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err,
+				dsrv.NewHandshakeError(dsrv.PhaseClientInfo, wantErr))
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If AuthKeys are set, Handle should authenticate the client",
+		func(t *testing.T) {
+			var (
+				key       = delegate.AuthKey{ID: "2", Secret: []byte("new secret")}
+				challenge delegate.AuthChallenge
+				conn      = cmock.NewConn()
+				transport = makeTransport(time.Now(), serverInfo,
+					wantServerInfoSendDuration, delta, t).RegisterSendAuthChallenge(
+					func(c delegate.AuthChallenge) (err error) {
+						asserterror.Equal(t, len(c.Nonce), delegate.NonceSize)
+						challenge = c
+						return nil
+					},
+				).RegisterReceiveAuthResponse(
+					func() (r delegate.AuthResponse, err error) {
+						return delegate.NewAuthResponse(key, challenge), nil
+					},
+				)
+				factory = makeTransportFactory(conn, registerAddrs(transport), t)
+				handler = srvmock.NewTransportHandler().RegisterHandle(
+					func(ctx context.Context, transport dsrv.Transport[any]) error {
+						return nil
+					},
+				)
+				delegate = dsrv.New(serverInfo, factory, handler,
+					append(ops, dsrv.WithAuthKeys(
+						delegate.AuthKey{ID: "1", Secret: []byte("old secret")},
+						key,
+					))...)
+				mocks = []*mok.Mock{
+					conn.Mock, transport.Mock, factory.Mock,
+					handler.Mock,
+				}
+			)
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If the client fails authentication, Handle should close the transport and return ErrAuthFailed",
+		func(t *testing.T) {
+			var (
+				wantErr   = dsrv.ErrAuthFailed
+				challenge delegate.AuthChallenge
+				conn      = cmock.NewConn()
+				transport = makeTransport(time.Now(), serverInfo,
+					wantServerInfoSendDuration, delta, t).RegisterSendAuthChallenge(
+					func(c delegate.AuthChallenge) (err error) {
+						challenge = c
+						return nil
+					},
+				).RegisterReceiveAuthResponse(
+					func() (r delegate.AuthResponse, err error) {
+						key := delegate.AuthKey{ID: "1", Secret: []byte("wrong secret")}
+						return delegate.NewAuthResponse(key, challenge), nil
+					},
+				).RegisterClose(
+					func() (err error) { return nil },
+				)
+				factory  = makeTransportFactory(conn, transport, t)
+				delegate = dsrv.New(serverInfo, factory, nil,
+					append(ops, dsrv.WithAuthKeys(
+						delegate.AuthKey{ID: "1", Secret: []byte("secret")},
+					))...)
+				mocks = []*mok.Mock{conn.Mock, transport.Mock, factory.Mock}
+			)
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err,
+				dsrv.NewHandshakeError(dsrv.PhaseAuth, wantErr))
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If WithPreamble is used, Handle should send Preamble before ServerInfo",
+		func(t *testing.T) {
+			var (
+				conn      = cmock.NewConn()
+				transport = srvmock.NewTransport().RegisterSetSendDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterSendPreamble(
+					func(p delegate.Preamble) (err error) {
+						asserterror.Equal(t, p, delegate.NewPreamble())
+						return nil
+					},
+				).RegisterSendServerInfo(
+					func(info delegate.ServerInfo) (err error) { return nil },
+				)
+				factory = makeTransportFactory(conn, registerAddrs(transport), t)
+				handler = srvmock.NewTransportHandler().RegisterHandle(
+					func(ctx context.Context, transport dsrv.Transport[any]) error {
+						return nil
+					},
+				)
+				delegate = dsrv.New(serverInfo, factory, handler,
+					append(ops, dsrv.WithPreamble())...)
+				mocks = []*mok.Mock{
+					conn.Mock, transport.Mock, factory.Mock,
+					handler.Mock,
+				}
+			)
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If Admission rejects the connection, Handle should send the reason, close the transport and return ErrRejected",
+		func(t *testing.T) {
+			var (
+				wantErr = fmt.Errorf("%w: %v", dsrv.ErrRejected,
+					delegate.RejectBusy)
+				conn      = cmock.NewConn()
+				admission = srvmock.NewAdmission().RegisterAdmit(
+					func(c net.Conn) delegate.RejectReason {
+						return delegate.RejectBusy
+					},
+				)
+				transport = srvmock.NewTransport().RegisterSetSendDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterSendAdmission(
+					func(reason delegate.RejectReason) (err error) {
+						asserterror.Equal(t, reason, delegate.RejectBusy)
+						return nil
+					},
+				).RegisterClose(
+					func() (err error) { return nil },
+				)
+				factory  = makeTransportFactory(conn, transport, t)
+				delegate = dsrv.New(serverInfo, factory, nil,
+					append(ops, dsrv.WithAdmission(admission))...)
+				mocks = []*mok.Mock{
+					conn.Mock, transport.Mock, factory.Mock,
+					admission.Mock,
+				}
+			)
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err,
+				dsrv.NewHandshakeError(dsrv.PhaseServerInfo, wantErr))
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If Admission accepts the connection, Handle should send RejectNone and release the connection after handling",
+		func(t *testing.T) {
+			var (
+				handled   bool
+				conn      = cmock.NewConn()
+				admission = srvmock.NewAdmission().RegisterAdmit(
+					func(c net.Conn) delegate.RejectReason {
+						return delegate.RejectNone
+					},
+				).RegisterRelease(
+					func(c net.Conn) {
+						if !handled {
+							t.Error("connection released before handling")
+						}
+					},
+				)
+				transport = srvmock.NewTransport().RegisterSetSendDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterSendAdmission(
+					func(reason delegate.RejectReason) (err error) {
+						asserterror.Equal(t, reason, delegate.RejectNone)
+						return nil
+					},
+				).RegisterSendServerInfo(
+					func(info delegate.ServerInfo) (err error) { return nil },
+				)
+				factory = makeTransportFactory(conn, registerAddrs(transport), t)
+				handler = srvmock.NewTransportHandler().RegisterHandle(
+					func(ctx context.Context, transport dsrv.Transport[any]) error {
+						handled = true
+						return nil
+					},
+				)
+				delegate = dsrv.New(serverInfo, factory, handler,
+					append(ops, dsrv.WithAdmission(admission))...)
+				mocks = []*mok.Mock{
+					conn.Mock, transport.Mock, factory.Mock,
+					handler.Mock, admission.Mock,
+				}
+			)
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If RedirectPolicy redirects the connection, Handle should send Redirect, close the transport and return ErrRedirected",
+		func(t *testing.T) {
+			var (
+				wantAddr = "127.0.0.1:9001"
+				wantErr  = fmt.Errorf("%w to %v", dsrv.ErrRedirected, wantAddr)
+				conn     = cmock.NewConn()
+				policy   = dsrv.RedirectFunc(func(c net.Conn) (string, bool) {
+					return wantAddr, true
+				})
+				transport = srvmock.NewTransport().RegisterSetSendDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterSendAdmission(
+					func(reason delegate.RejectReason) (err error) {
+						asserterror.Equal(t, reason, delegate.RejectRedirect)
+						return nil
+					},
+				).RegisterSendRedirect(
+					func(r delegate.Redirect) (err error) {
+						asserterror.Equal(t, r, delegate.Redirect{Addr: wantAddr})
+						return nil
+					},
+				).RegisterClose(
+					func() (err error) { return nil },
+				)
+				factory  = makeTransportFactory(conn, transport, t)
+				delegate = dsrv.New(serverInfo, factory, nil,
+					append(ops, dsrv.WithRedirectPolicy(policy))...)
+				mocks = []*mok.Mock{conn.Mock, transport.Mock, factory.Mock}
+			)
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err,
+				dsrv.NewHandshakeError(dsrv.PhaseServerInfo, wantErr))
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
 		})
 }
 
@@ -135,3 +549,16 @@ func makeTransport(startTime time.Time, info delegate.ServerInfo,
 		},
 	)
 }
+
+var (
+	localAddr  = &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 9000}
+	remoteAddr = &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 9001}
+)
+
+func registerAddrs(transport srvmock.Transport) srvmock.Transport {
+	return transport.RegisterLocalAddr(
+		func() (addr net.Addr) { return localAddr },
+	).RegisterRemoteAddr(
+		func() (addr net.Addr) { return remoteAddr },
+	)
+}
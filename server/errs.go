@@ -4,3 +4,25 @@ import "errors"
 
 // ErrEmptyInfo happens when ServerInfo is empty during Delegate creation.
 var ErrEmptyInfo = errors.New("empty info")
+
+// ErrRejected happens when Admission rejects the connection.
+var ErrRejected = errors.New("connection rejected")
+
+// ErrShuttingDown happens when TrackedDelegate receives a connection after
+// Drain or Shutdown.
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// ErrRedirected happens when RedirectPolicy redirects the connection.
+var ErrRedirected = errors.New("connection redirected")
+
+// ErrIdleTimeout happens when IdleTransport receives no Commands within the
+// idle timeout.
+var ErrIdleTimeout = errors.New("idle timeout")
+
+// ErrRateLimited happens when RateLimitTransport receives a Command over the
+// limit.
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrAuthFailed happens when the client fails to answer the authentication
+// challenge with any of the configured keys.
+var ErrAuthFailed = errors.New("authentication failed")
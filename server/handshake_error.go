@@ -0,0 +1,51 @@
+package server
+
+import "fmt"
+
+// HandshakePhase identifies a phase of the server handshake.
+type HandshakePhase int
+
+const (
+	// PhaseServerInfo covers sending Preamble, the admission frame and
+	// ServerInfo.
+	PhaseServerInfo HandshakePhase = iota
+	// PhaseAuth covers the authentication challenge.
+	PhaseAuth
+	// PhaseClientInfo covers receiving and validating ClientInfo.
+	PhaseClientInfo
+)
+
+func (p HandshakePhase) String() string {
+	switch p {
+	case PhaseServerInfo:
+		return "server info"
+	case PhaseAuth:
+		return "auth"
+	case PhaseClientInfo:
+		return "client info"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(p))
+	}
+}
+
+// NewHandshakeError creates a new HandshakeError.
+func NewHandshakeError(phase HandshakePhase, err error) HandshakeError {
+	return HandshakeError{Phase: phase, Err: err}
+}
+
+// HandshakeError is returned by Delegate.Handle when the handshake fails.
+//
+// Phase is the phase that failed. If closing the Transport after the failure
+// also fails, Err joins both errors with errors.Join.
+type HandshakeError struct {
+	Phase HandshakePhase
+	Err   error
+}
+
+func (e HandshakeError) Error() string {
+	return fmt.Sprintf("handshake failed on %v: %v", e.Phase, e.Err)
+}
+
+func (e HandshakeError) Unwrap() error {
+	return e.Err
+}
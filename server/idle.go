@@ -0,0 +1,81 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/cmd-stream/core-go"
+)
+
+// KeepaliveIdleTimeout returns an idle timeout for clients that use
+// KeepaliveDelegate with the given KeepaliveTime and KeepaliveIntvl. Such a
+// client is considered gone after missing n Ping Commands in a row.
+func KeepaliveIdleTimeout(keepaliveTime, keepaliveIntvl time.Duration,
+	n int,
+) time.Duration {
+	return keepaliveTime + time.Duration(n)*keepaliveIntvl
+}
+
+// NewIdleTransport creates a new IdleTransport.
+func NewIdleTransport[T any](transport Transport[T],
+	timeout time.Duration,
+) *IdleTransport[T] {
+	return &IdleTransport[T]{Transport: transport, timeout: timeout}
+}
+
+// IdleTransport closes the connection if no Command (including Ping) is
+// received within the idle timeout, so half-open connections are reclaimed.
+//
+// In this case Receive returns ErrIdleTimeout. A receive deadline set with
+// SetReceiveDeadline is still respected if it comes earlier.
+type IdleTransport[T any] struct {
+	Transport[T]
+	timeout  time.Duration
+	deadline time.Time
+	idle     atomic.Bool
+}
+
+// SetReceiveDeadline sets the deadline for the next Receive. It is applied by
+// Receive together with the idle timeout.
+func (t *IdleTransport[T]) SetReceiveDeadline(deadline time.Time) error {
+	t.deadline = deadline
+	return nil
+}
+
+func (t *IdleTransport[T]) Receive() (seq core.Seq, cmd core.Cmd[T], n int,
+	err error,
+) {
+	var (
+		idleDeadline = time.Now().Add(t.timeout)
+		deadline     = idleDeadline
+	)
+	if !t.deadline.IsZero() && t.deadline.Before(idleDeadline) {
+		deadline = t.deadline
+	}
+	if err = t.Transport.SetReceiveDeadline(deadline); err != nil {
+		return
+	}
+	seq, cmd, n, err = t.Transport.Receive()
+	if err != nil && deadline.Equal(idleDeadline) && isTimeout(err) {
+		t.idle.Store(true)
+		t.Transport.Close()
+		err = ErrIdleTimeout
+	}
+	return
+}
+
+// Close closes the underlying Transport, unless it has already been closed
+// because of the idle timeout.
+func (t *IdleTransport[T]) Close() error {
+	if t.idle.Load() {
+		return nil
+	}
+	return t.Transport.Close()
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,96 @@
+package server_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cmd-stream/core-go"
+	dsrv "github.com/cmd-stream/delegate-go/server"
+	srvmock "github.com/cmd-stream/delegate-go/test/mock/server"
+	asserterror "github.com/ymz-ncnk/assert/error"
+	"github.com/ymz-ncnk/mok"
+)
+
+func TestIdleTransport(t *testing.T) {
+	var (
+		delta   = 100 * time.Millisecond
+		timeout = time.Minute
+	)
+
+	t.Run("Receive should set the idle deadline", func(t *testing.T) {
+		var (
+			wantSeq   core.Seq = 1
+			startTime          = time.Now()
+			transport          = srvmock.NewTransport().RegisterSetReceiveDeadline(
+				func(deadline time.Time) (err error) {
+					asserterror.SameTime(t, deadline, startTime.Add(timeout), delta)
+					return nil
+				},
+			).RegisterReceive(
+				func() (seq core.Seq, cmd core.Cmd[any], n int, err error) {
+					return wantSeq, nil, 1, nil
+				},
+			)
+			idle  = dsrv.NewIdleTransport[any](transport, timeout)
+			mocks = []*mok.Mock{transport.Mock}
+		)
+		seq, _, _, err := idle.Receive()
+		asserterror.EqualError(t, err, nil)
+		asserterror.Equal(t, seq, wantSeq)
+		asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+	})
+
+	t.Run("If an earlier deadline is set, Receive should use it", func(t *testing.T) {
+		var (
+			wantErr      = os.ErrDeadlineExceeded
+			wantDeadline = time.Now().Add(time.Second)
+			transport    = srvmock.NewTransport().RegisterSetReceiveDeadline(
+				func(deadline time.Time) (err error) {
+					asserterror.Equal(t, deadline, wantDeadline)
+					return nil
+				},
+			).RegisterReceive(
+				func() (seq core.Seq, cmd core.Cmd[any], n int, err error) {
+					return 0, nil, 0, wantErr
+				},
+			)
+			idle  = dsrv.NewIdleTransport[any](transport, timeout)
+			mocks = []*mok.Mock{transport.Mock}
+		)
+		err := idle.SetReceiveDeadline(wantDeadline)
+		asserterror.EqualError(t, err, nil)
+		_, _, _, err = idle.Receive()
+		asserterror.EqualError(t, err, wantErr)
+		asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+	})
+
+	t.Run("If the idle timeout expires, Receive should close the transport and return ErrIdleTimeout",
+		func(t *testing.T) {
+			var (
+				wantErr   = dsrv.ErrIdleTimeout
+				transport = srvmock.NewTransport().RegisterSetReceiveDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterReceive(
+					func() (seq core.Seq, cmd core.Cmd[any], n int, err error) {
+						return 0, nil, 0, os.ErrDeadlineExceeded
+					},
+				).RegisterClose(
+					func() (err error) { return nil },
+				)
+				idle  = dsrv.NewIdleTransport[any](transport, timeout)
+				mocks = []*mok.Mock{transport.Mock}
+			)
+			_, _, _, err := idle.Receive()
+			asserterror.EqualError(t, err, wantErr)
+			// The transport is already closed, so Close should not close it again.
+			err = idle.Close()
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+}
+
+func TestKeepaliveIdleTimeout(t *testing.T) {
+	timeout := dsrv.KeepaliveIdleTimeout(time.Minute, 10*time.Second, 3)
+	asserterror.Equal(t, timeout, 90*time.Second)
+}
@@ -0,0 +1,47 @@
+package server
+
+import "context"
+
+// TransportHandlerFunc allows a function to be used as a TransportHandler.
+type TransportHandlerFunc[T any] func(ctx context.Context,
+	transport Transport[T]) error
+
+func (f TransportHandlerFunc[T]) Handle(ctx context.Context,
+	transport Transport[T],
+) error {
+	return f(ctx, transport)
+}
+
+// Middleware wraps a TransportHandler to add cross-cutting behavior, such as
+// logging or metrics.
+type Middleware[T any] func(handler TransportHandler[T]) TransportHandler[T]
+
+// TransportDecorator wraps a Transport.
+type TransportDecorator[T any] func(transport Transport[T]) Transport[T]
+
+// Chain wraps handler in the Middlewares. The first Middleware is the
+// outermost one, i.e. it is called first. The result can be passed to New.
+func Chain[T any](handler TransportHandler[T],
+	mws ...Middleware[T],
+) TransportHandler[T] {
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+	return handler
+}
+
+// DecorateTransport returns a Middleware, which wraps the Transport in the
+// TransportDecorators before passing it to the next TransportHandler. The
+// first TransportDecorator is the outermost one.
+func DecorateTransport[T any](decorators ...TransportDecorator[T]) Middleware[T] {
+	return func(handler TransportHandler[T]) TransportHandler[T] {
+		return TransportHandlerFunc[T](func(ctx context.Context,
+			transport Transport[T],
+		) error {
+			for i := len(decorators) - 1; i >= 0; i-- {
+				transport = decorators[i](transport)
+			}
+			return handler.Handle(ctx, transport)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cmock "github.com/cmd-stream/core-go/test/mock"
+	"github.com/cmd-stream/delegate-go"
+	dsrv "github.com/cmd-stream/delegate-go/server"
+	srvmock "github.com/cmd-stream/delegate-go/test/mock/server"
+	asserterror "github.com/ymz-ncnk/assert/error"
+	"github.com/ymz-ncnk/mok"
+)
+
+func TestMiddleware(t *testing.T) {
+	var (
+		delta                      = 100 * time.Millisecond
+		wantServerInfoSendDuration = time.Second
+		ops                        = []dsrv.SetOption{
+			dsrv.WithServerInfoSendDuration(wantServerInfoSendDuration),
+		}
+		serverInfo = delegate.ServerInfo([]byte("server info"))
+	)
+
+	t.Run("Chain should call Middlewares in order", func(t *testing.T) {
+		var (
+			wantCalls = []string{"first", "second", "handler"}
+			calls     []string
+			handler   = dsrv.TransportHandlerFunc[any](
+				func(ctx context.Context, transport dsrv.Transport[any]) error {
+					calls = append(calls, "handler")
+					return nil
+				})
+			chain = dsrv.Chain[any](handler,
+				makeMiddleware[any]("first", &calls),
+				makeMiddleware[any]("second", &calls),
+			)
+		)
+		err := chain.Handle(context.Background(), nil)
+		asserterror.EqualError(t, err, nil)
+		asserterror.EqualDeep(t, calls, wantCalls)
+	})
+
+	t.Run("DecorateTransport should pass the decorated Transport to the handler",
+		func(t *testing.T) {
+			var (
+				transport = srvmock.NewTransport()
+				handler   = dsrv.TransportHandlerFunc[any](
+					func(ctx context.Context, tran dsrv.Transport[any]) error {
+						d, ok := tran.(decoratedTransport)
+						if !ok || d.name != "first" {
+							t.Errorf("unexpected transport %v", tran)
+							return nil
+						}
+						if d, ok = d.Transport.(decoratedTransport); !ok ||
+							d.name != "second" {
+							t.Errorf("unexpected transport %v", d.Transport)
+						}
+						return nil
+					})
+				chain = dsrv.Chain[any](handler, dsrv.DecorateTransport(
+					makeDecorator("first"),
+					makeDecorator("second"),
+				))
+			)
+			err := chain.Handle(context.Background(), transport)
+			asserterror.EqualError(t, err, nil)
+		})
+
+	t.Run("If the handler is wrapped with Chain, Handle should call the Middlewares",
+		func(t *testing.T) {
+			var (
+				wantCalls = []string{"first", "second"}
+				calls     []string
+				conn      = cmock.NewConn()
+				transport = registerAddrs(makeTransport(time.Now(), serverInfo,
+					wantServerInfoSendDuration, delta, t))
+				factory = makeTransportFactory(conn, transport, t)
+				handler = srvmock.NewTransportHandler().RegisterHandle(
+					func(ctx context.Context, transport dsrv.Transport[any]) error {
+						return nil
+					},
+				)
+				delegate = dsrv.New(serverInfo, factory,
+					dsrv.Chain[any](handler,
+						makeMiddleware[any]("first", &calls),
+						makeMiddleware[any]("second", &calls),
+					),
+					ops...)
+				mocks = []*mok.Mock{
+					conn.Mock, transport.Mock, factory.Mock,
+					handler.Mock,
+				}
+			)
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualDeep(t, calls, wantCalls)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+}
+
+func makeMiddleware[T any](name string, calls *[]string) dsrv.Middleware[T] {
+	return func(handler dsrv.TransportHandler[T]) dsrv.TransportHandler[T] {
+		return dsrv.TransportHandlerFunc[T](
+			func(ctx context.Context, transport dsrv.Transport[T]) error {
+				*calls = append(*calls, name)
+				return handler.Handle(ctx, transport)
+			})
+	}
+}
+
+func makeDecorator(name string) dsrv.TransportDecorator[any] {
+	return func(transport dsrv.Transport[any]) dsrv.Transport[any] {
+		return decoratedTransport{transport, name}
+	}
+}
+
+type decoratedTransport struct {
+	dsrv.Transport[any]
+	name string
+}
@@ -1,9 +1,20 @@
 package server
 
-import "time"
+import (
+	"time"
+
+	"github.com/cmd-stream/delegate-go"
+)
 
 type Options struct {
-	ServerInfoSendDuration time.Duration
+	ServerInfoSendDuration   time.Duration
+	HandshakeReceiveDuration time.Duration
+	ClientInfoValidator      ClientInfoValidator
+	AuthKeys                 []delegate.AuthKey
+	Preamble                 bool
+	Admission                Admission
+	RedirectPolicy           RedirectPolicy
+	IdleTimeout              time.Duration
 }
 
 type SetOption func(o *Options)
@@ -14,6 +25,60 @@ func WithServerInfoSendDuration(d time.Duration) SetOption {
 	return func(o *Options) { o.ServerInfoSendDuration = d }
 }
 
+// WithHandshakeReceiveDuration specifies how long the server will wait for
+// the client's part of the handshake (AuthResponse and ClientInfo). If == 0,
+// it will wait forever.
+func WithHandshakeReceiveDuration(d time.Duration) SetOption {
+	return func(o *Options) { o.HandshakeReceiveDuration = d }
+}
+
+// WithClientInfoValidator makes the server receive ClientInfo after sending
+// ServerInfo and validate it. The received ClientInfo is available to the
+// TransportHandler through ClientInfoFrom.
+func WithClientInfoValidator(v ClientInfoValidator) SetOption {
+	return func(o *Options) { o.ClientInfoValidator = v }
+}
+
+// WithAuthKeys makes the server authenticate clients with an HMAC-SHA256
+// challenge after sending ServerInfo. A client must sign the challenge with
+// one of the keys, otherwise the connection is closed with ErrAuthFailed.
+// Several keys allow the secret to be rotated.
+func WithAuthKeys(keys ...delegate.AuthKey) SetOption {
+	return func(o *Options) { o.AuthKeys = keys }
+}
+
+// WithPreamble makes the server send delegate.Preamble before ServerInfo.
+// The client must be configured to expect it.
+func WithPreamble() SetOption {
+	return func(o *Options) { o.Preamble = true }
+}
+
+// WithAdmission makes the server consult Admission for each new connection
+// and send the admission frame after the Preamble. A rejected connection
+// receives the rejection reason instead of ServerInfo and is closed. The
+// client must be configured to expect the admission frame.
+func WithAdmission(a Admission) SetOption {
+	return func(o *Options) { o.Admission = a }
+}
+
+// WithRedirectPolicy makes the server consult RedirectPolicy for each new
+// connection before Admission. A redirected connection receives the
+// delegate.RejectRedirect admission frame followed by delegate.Redirect
+// instead of ServerInfo, and is closed. The client must be configured to
+// expect the admission frame.
+func WithRedirectPolicy(p RedirectPolicy) SetOption {
+	return func(o *Options) { o.RedirectPolicy = p }
+}
+
+// WithIdleTimeout makes the server wrap each Transport passed to the
+// TransportHandler in IdleTransport, so a connection is closed if no
+// Commands are received within d. For clients with KeepaliveDelegate, d can
+// be calculated with KeepaliveIdleTimeout. If == 0, idle connections are not
+// closed.
+func WithIdleTimeout(d time.Duration) SetOption {
+	return func(o *Options) { o.IdleTimeout = d }
+}
+
 func Apply(ops []SetOption, o *Options) {
 	for i := range ops {
 		if ops[i] != nil {
@@ -21,3 +86,30 @@ func Apply(ops []SetOption, o *Options) {
 		}
 	}
 }
+
+type RateLimitOptions struct {
+	Action RateLimitAction
+	Global *TokenBucket
+}
+
+type SetRateLimitOption func(o *RateLimitOptions)
+
+// WithRateLimitAction sets what RateLimitTransport does when a Command
+// exceeds the limit. Defaults to RateLimitDelay.
+func WithRateLimitAction(a RateLimitAction) SetRateLimitOption {
+	return func(o *RateLimitOptions) { o.Action = a }
+}
+
+// WithGlobalRateLimit sets a TokenBucket shared by all connections, in
+// addition to the per-connection one.
+func WithGlobalRateLimit(b *TokenBucket) SetRateLimitOption {
+	return func(o *RateLimitOptions) { o.Global = b }
+}
+
+func ApplyRateLimit(ops []SetRateLimitOption, o *RateLimitOptions) {
+	for i := range ops {
+		if ops[i] != nil {
+			ops[i](o)
+		}
+	}
+}
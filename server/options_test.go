@@ -1,21 +1,88 @@
 package server
 
 import (
+	"net"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/cmd-stream/delegate-go"
 )
 
 func TestOptions(t *testing.T) {
 	var (
-		o                          = Options{}
-		wantServerInfoSendDuration = time.Second
+		o                            = Options{}
+		wantServerInfoSendDuration   = time.Second
+		wantHandshakeReceiveDuration = 2 * time.Second
+		wantAuthKeys                 = []delegate.AuthKey{
+			{ID: "1", Secret: []byte("secret")},
+		}
+		wantAdmission      = NewMaxConnsAdmission(10)
+		wantIdleTimeout    = time.Minute
+		wantRedirectPolicy = RedirectFunc(func(conn net.Conn) (string, bool) {
+			return "", false
+		})
 	)
 	Apply([]SetOption{
 		WithServerInfoSendDuration(wantServerInfoSendDuration),
+		WithHandshakeReceiveDuration(wantHandshakeReceiveDuration),
+		WithAuthKeys(wantAuthKeys...),
+		WithPreamble(),
+		WithAdmission(wantAdmission),
+		WithRedirectPolicy(wantRedirectPolicy),
+		WithIdleTimeout(wantIdleTimeout),
 	}, &o)
 
 	if o.ServerInfoSendDuration != wantServerInfoSendDuration {
 		t.Errorf("unexpected ServerInfoSendDuration, want %v actual %v",
 			wantServerInfoSendDuration, o.ServerInfoSendDuration)
 	}
+
+	if o.HandshakeReceiveDuration != wantHandshakeReceiveDuration {
+		t.Errorf("unexpected HandshakeReceiveDuration, want %v actual %v",
+			wantHandshakeReceiveDuration, o.HandshakeReceiveDuration)
+	}
+
+	if !reflect.DeepEqual(o.AuthKeys, wantAuthKeys) {
+		t.Errorf("unexpected AuthKeys, want %v actual %v", wantAuthKeys,
+			o.AuthKeys)
+	}
+
+	if !o.Preamble {
+		t.Error("unexpected Preamble, want true actual false")
+	}
+
+	if o.Admission != wantAdmission {
+		t.Errorf("unexpected Admission, want %v actual %v", wantAdmission,
+			o.Admission)
+	}
+
+	if o.RedirectPolicy == nil {
+		t.Error("unexpected RedirectPolicy, want not nil actual nil")
+	}
+
+	if o.IdleTimeout != wantIdleTimeout {
+		t.Errorf("unexpected IdleTimeout, want %v actual %v", wantIdleTimeout,
+			o.IdleTimeout)
+	}
+}
+
+func TestRateLimitOptions(t *testing.T) {
+	var (
+		o          = RateLimitOptions{}
+		wantAction = RateLimitDrop
+		wantGlobal = NewTokenBucket(100, 10)
+	)
+	ApplyRateLimit([]SetRateLimitOption{
+		WithRateLimitAction(wantAction),
+		WithGlobalRateLimit(wantGlobal),
+	}, &o)
+
+	if o.Action != wantAction {
+		t.Errorf("unexpected Action, want %v actual %v", wantAction, o.Action)
+	}
+
+	if o.Global != wantGlobal {
+		t.Errorf("unexpected Global, want %v actual %v", wantGlobal, o.Global)
+	}
 }
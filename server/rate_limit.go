@@ -0,0 +1,99 @@
+package server
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cmd-stream/core-go"
+)
+
+// RateLimitAction defines what RateLimitTransport does when a Command exceeds
+// the limit.
+type RateLimitAction int
+
+const (
+	// RateLimitDelay delays the Command until a token is available.
+	RateLimitDelay RateLimitAction = iota
+	// RateLimitDrop closes the connection and returns ErrRateLimited.
+	RateLimitDrop
+	// RateLimitError returns the Command together with ErrRateLimited, leaving
+	// the decision to the TransportHandler.
+	RateLimitError
+)
+
+// NewRateLimitTransport creates a new RateLimitTransport, which allows rate
+// Commands per second with bursts of up to burst Commands.
+func NewRateLimitTransport[T any](transport Transport[T], rate float64,
+	burst int,
+	ops ...SetRateLimitOption,
+) *RateLimitTransport[T] {
+	t := &RateLimitTransport[T]{
+		Transport: transport,
+		bucket:    NewTokenBucket(rate, burst),
+	}
+	ApplyRateLimit(ops, &t.options)
+	return t
+}
+
+// RateLimitDecorator returns a TransportDecorator, which wraps each Transport
+// in its own RateLimitTransport. It can be used with DecorateTransport.
+func RateLimitDecorator[T any](rate float64, burst int,
+	ops ...SetRateLimitOption,
+) TransportDecorator[T] {
+	return func(transport Transport[T]) Transport[T] {
+		return NewRateLimitTransport(transport, rate, burst, ops...)
+	}
+}
+
+// RateLimitTransport limits the rate of Commands received from a single
+// connection with a token bucket. Optionally, a global TokenBucket shared by
+// several connections can be configured with WithGlobalRateLimit.
+type RateLimitTransport[T any] struct {
+	Transport[T]
+	bucket  *TokenBucket
+	options RateLimitOptions
+}
+
+func (t *RateLimitTransport[T]) Receive() (seq core.Seq, cmd core.Cmd[T],
+	n int, err error,
+) {
+	seq, cmd, n, err = t.Transport.Receive()
+	if err != nil {
+		return
+	}
+	err = t.limit()
+	return
+}
+
+func (t *RateLimitTransport[T]) limit() error {
+	if t.options.Action == RateLimitDelay {
+		wait := t.bucket.Reserve()
+		if t.options.Global != nil {
+			wait = max(wait, t.options.Global.Reserve())
+		}
+		if wait > 0 {
+			time.Sleep(wait)
+		}
+		return nil
+	}
+	if t.allow() {
+		return nil
+	}
+	if t.options.Action == RateLimitDrop {
+		if err := t.Transport.Close(); err != nil {
+			return errors.Join(ErrRateLimited, err)
+		}
+	}
+	return ErrRateLimited
+}
+
+func (t *RateLimitTransport[T]) allow() bool {
+	if !t.bucket.Allow() {
+		return false
+	}
+	if t.options.Global != nil && !t.options.Global.Allow() {
+		t.bucket.cancel()
+		return false
+	}
+	return true
+}
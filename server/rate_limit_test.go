@@ -0,0 +1,118 @@
+package server_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cmd-stream/core-go"
+	dsrv "github.com/cmd-stream/delegate-go/server"
+	srvmock "github.com/cmd-stream/delegate-go/test/mock/server"
+	asserterror "github.com/ymz-ncnk/assert/error"
+	"github.com/ymz-ncnk/mok"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t.Run("Allow should take tokens until the bucket is empty",
+		func(t *testing.T) {
+			b := dsrv.NewTokenBucket(1, 2)
+			asserterror.Equal(t, b.Allow(), true)
+			asserterror.Equal(t, b.Allow(), true)
+			asserterror.Equal(t, b.Allow(), false)
+		})
+
+	t.Run("Reserve should return the wait for the next token", func(t *testing.T) {
+		var (
+			delta = 10 * time.Millisecond
+			b     = dsrv.NewTokenBucket(10, 1)
+		)
+		asserterror.Equal(t, b.Reserve(), time.Duration(0))
+		wait := b.Reserve()
+		if wait < 100*time.Millisecond-delta || wait > 100*time.Millisecond {
+			t.Errorf("unexpected wait %v", wait)
+		}
+	})
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	var (
+		delta   = 50 * time.Millisecond
+		receive = func() (seq core.Seq, cmd core.Cmd[any], n int, err error) {
+			return 1, nil, 1, nil
+		}
+	)
+
+	t.Run("By default, Receive should delay Commands over the limit",
+		func(t *testing.T) {
+			var (
+				transport = srvmock.NewTransport().RegisterNReceive(2, receive)
+				limited   = dsrv.NewRateLimitTransport[any](transport, 10, 1)
+				mocks     = []*mok.Mock{transport.Mock}
+			)
+			_, _, _, err := limited.Receive()
+			asserterror.EqualError(t, err, nil)
+			start := time.Now()
+			_, _, _, err = limited.Receive()
+			asserterror.EqualError(t, err, nil)
+			asserterror.SameTime(t, time.Now(), start.Add(100*time.Millisecond),
+				delta)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If Action is RateLimitDrop, Receive should close the transport and return ErrRateLimited",
+		func(t *testing.T) {
+			var (
+				wantErr   = dsrv.ErrRateLimited
+				transport = srvmock.NewTransport().RegisterNReceive(2,
+					receive).RegisterClose(
+					func() (err error) { return nil },
+				)
+				limited = dsrv.NewRateLimitTransport[any](transport, 1, 1,
+					dsrv.WithRateLimitAction(dsrv.RateLimitDrop))
+				mocks = []*mok.Mock{transport.Mock}
+			)
+			_, _, _, err := limited.Receive()
+			asserterror.EqualError(t, err, nil)
+			_, _, _, err = limited.Receive()
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If Action is RateLimitError, Receive should return the Command and ErrRateLimited",
+		func(t *testing.T) {
+			var (
+				wantErr   = dsrv.ErrRateLimited
+				transport = srvmock.NewTransport().RegisterNReceive(2, receive)
+				limited   = dsrv.NewRateLimitTransport[any](transport, 1, 1,
+					dsrv.WithRateLimitAction(dsrv.RateLimitError))
+				mocks = []*mok.Mock{transport.Mock}
+			)
+			_, _, _, err := limited.Receive()
+			asserterror.EqualError(t, err, nil)
+			seq, _, _, err := limited.Receive()
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.Equal(t, seq, core.Seq(1))
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If the global limit is exceeded, Receive should return ErrRateLimited",
+		func(t *testing.T) {
+			var (
+				wantErr    = dsrv.ErrRateLimited
+				global     = dsrv.NewTokenBucket(1, 1)
+				transport1 = srvmock.NewTransport().RegisterReceive(receive)
+				transport2 = srvmock.NewTransport().RegisterReceive(receive)
+				limited1   = dsrv.NewRateLimitTransport[any](transport1, 1, 1,
+					dsrv.WithRateLimitAction(dsrv.RateLimitError),
+					dsrv.WithGlobalRateLimit(global))
+				limited2 = dsrv.NewRateLimitTransport[any](transport2, 1, 1,
+					dsrv.WithRateLimitAction(dsrv.RateLimitError),
+					dsrv.WithGlobalRateLimit(global))
+				mocks = []*mok.Mock{transport1.Mock, transport2.Mock}
+			)
+			_, _, _, err := limited1.Receive()
+			asserterror.EqualError(t, err, nil)
+			_, _, _, err = limited2.Receive()
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+}
@@ -0,0 +1,17 @@
+package server
+
+import "net"
+
+// RedirectPolicy decides whether a new connection should be redirected to
+// another server. If ok is true, the client receives addr instead of
+// ServerInfo and the connection is closed.
+type RedirectPolicy interface {
+	Redirect(conn net.Conn) (addr string, ok bool)
+}
+
+// RedirectFunc allows a function to be used as a RedirectPolicy.
+type RedirectFunc func(conn net.Conn) (addr string, ok bool)
+
+func (f RedirectFunc) Redirect(conn net.Conn) (addr string, ok bool) {
+	return f(conn)
+}
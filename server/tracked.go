@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/cmd-stream/delegate-go"
+)
+
+// NewTracked creates a new TrackedDelegate.
+//
+// Panics with ErrEmptyInfo if ServerInfo is empty, use TryNewTracked to get
+// an error instead.
+func NewTracked[T any](info delegate.ServerInfo, factory TransportFactory[T],
+	handler TransportHandler[T],
+	opts ...SetOption,
+) (d *TrackedDelegate[T]) {
+	d, err := TryNewTracked(info, factory, handler, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// TryNewTracked creates a new TrackedDelegate.
+//
+// Returns ErrEmptyInfo if ServerInfo is empty.
+func TryNewTracked[T any](info delegate.ServerInfo,
+	factory TransportFactory[T],
+	handler TransportHandler[T],
+	opts ...SetOption,
+) (d *TrackedDelegate[T], err error) {
+	var o Options
+	Apply(opts, &o)
+	d = &TrackedDelegate[T]{
+		admission:  o.Admission != nil || o.RedirectPolicy != nil,
+		transports: map[uint64]Transport[T]{},
+	}
+	if d.admission {
+		var admission Admission = drainAdmission[T]{d}
+		if o.Admission != nil {
+			admission = NewAdmissionChain(admission, o.Admission)
+		}
+		opts = append(opts, WithAdmission(admission))
+	}
+	d.delegate, err = TryNew(info, factory, trackingHandler[T]{d, handler},
+		opts...)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// TrackedDelegate is a Delegate that keeps track of the transports passed to
+// the TransportHandler, so that the server can be shut down gracefully.
+//
+// After Drain, new connections are rejected with delegate.RejectShuttingDown
+// if the admission frame is enabled (WithAdmission or WithRedirectPolicy
+// option), otherwise they are closed right away.
+type TrackedDelegate[T any] struct {
+	delegate   Delegate[T]
+	admission  bool
+	mu         sync.Mutex
+	draining   bool
+	closed     bool
+	transports map[uint64]Transport[T]
+	wg         sync.WaitGroup
+}
+
+func (d *TrackedDelegate[T]) Handle(ctx context.Context, conn net.Conn) (
+	err error,
+) {
+	d.mu.Lock()
+	if d.draining {
+		d.mu.Unlock()
+		if d.admission {
+			return d.delegate.Handle(ctx, conn)
+		}
+		conn.Close()
+		return ErrShuttingDown
+	}
+	d.wg.Add(1)
+	d.mu.Unlock()
+	defer d.wg.Done()
+	return d.delegate.Handle(ctx, conn)
+}
+
+// Drain makes the delegate reject new connections. Connections that have
+// already been accepted are not affected.
+func (d *TrackedDelegate[T]) Drain() {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+}
+
+// Shutdown drains the delegate and waits for all accepted connections to be
+// handled. If ctx is done first, the remaining transports are closed and
+// ctx.Err() is returned, joined with the close errors, if any.
+func (d *TrackedDelegate[T]) Shutdown(ctx context.Context) (err error) {
+	d.Drain()
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	errs := []error{ctx.Err()}
+	for _, transport := range d.transports {
+		if err = transport.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+func (d *TrackedDelegate[T]) track(id uint64, transport Transport[T]) (
+	ok bool,
+) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return false
+	}
+	d.transports[id] = transport
+	return true
+}
+
+func (d *TrackedDelegate[T]) untrack(id uint64) {
+	d.mu.Lock()
+	delete(d.transports, id)
+	d.mu.Unlock()
+}
+
+func (d *TrackedDelegate[T]) isDraining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining
+}
+
+type trackingHandler[T any] struct {
+	d       *TrackedDelegate[T]
+	handler TransportHandler[T]
+}
+
+func (h trackingHandler[T]) Handle(ctx context.Context,
+	transport Transport[T],
+) (err error) {
+	info, _ := ConnInfoFrom(ctx)
+	if !h.d.track(info.ID, transport) {
+		transport.Close()
+		return ErrShuttingDown
+	}
+	defer h.d.untrack(info.ID)
+	return h.handler.Handle(ctx, transport)
+}
+
+type drainAdmission[T any] struct {
+	d *TrackedDelegate[T]
+}
+
+func (a drainAdmission[T]) Admit(conn net.Conn) delegate.RejectReason {
+	if a.d.isDraining() {
+		return delegate.RejectShuttingDown
+	}
+	return delegate.RejectNone
+}
+
+func (a drainAdmission[T]) Release(conn net.Conn) {}
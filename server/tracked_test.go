@@ -0,0 +1,154 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	cmock "github.com/cmd-stream/core-go/test/mock"
+	"github.com/cmd-stream/delegate-go"
+	dsrv "github.com/cmd-stream/delegate-go/server"
+	srvmock "github.com/cmd-stream/delegate-go/test/mock/server"
+	asserterror "github.com/ymz-ncnk/assert/error"
+	"github.com/ymz-ncnk/mok"
+)
+
+func TestTrackedDelegate(t *testing.T) {
+	var (
+		delta                      = 100 * time.Millisecond
+		wantServerInfoSendDuration = time.Second
+		ops                        = []dsrv.SetOption{
+			dsrv.WithServerInfoSendDuration(wantServerInfoSendDuration),
+		}
+		serverInfo = delegate.ServerInfo([]byte("server info"))
+	)
+
+	t.Run("Shutdown should wait for the accepted connections to be handled",
+		func(t *testing.T) {
+			var (
+				handling  = make(chan struct{})
+				release   = make(chan struct{})
+				handled   = make(chan struct{})
+				conn      = cmock.NewConn()
+				transport = registerAddrs(makeTransport(time.Now(), serverInfo,
+					wantServerInfoSendDuration, delta, t))
+				factory = makeTransportFactory(conn, transport, t)
+				handler = srvmock.NewTransportHandler().RegisterHandle(
+					func(ctx context.Context, transport dsrv.Transport[any]) error {
+						close(handling)
+						<-release
+						close(handled)
+						return nil
+					},
+				)
+				delegate = dsrv.NewTracked(serverInfo, factory, handler, ops...)
+				mocks    = []*mok.Mock{
+					conn.Mock, transport.Mock, factory.Mock,
+					handler.Mock,
+				}
+				errs = make(chan error, 1)
+			)
+			go func() { errs <- delegate.Handle(context.Background(), conn) }()
+			<-handling
+			time.AfterFunc(delta, func() { close(release) })
+			err := delegate.Shutdown(context.Background())
+			asserterror.EqualError(t, err, nil)
+			select {
+			case <-handled:
+			default:
+				t.Error("Shutdown returned before the connection was handled")
+			}
+			asserterror.EqualError(t, <-errs, nil)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If ctx is done, Shutdown should close the remaining transports",
+		func(t *testing.T) {
+			var (
+				wantErr   = context.DeadlineExceeded
+				handling  = make(chan struct{})
+				closed    = make(chan struct{})
+				conn      = cmock.NewConn()
+				transport = registerAddrs(makeTransport(time.Now(), serverInfo,
+					wantServerInfoSendDuration, delta, t)).RegisterClose(
+					func() (err error) {
+						close(closed)
+						return nil
+					},
+				)
+				factory = makeTransportFactory(conn, transport, t)
+				handler = srvmock.NewTransportHandler().RegisterHandle(
+					func(ctx context.Context, transport dsrv.Transport[any]) error {
+						close(handling)
+						<-closed
+						return errors.New("closed")
+					},
+				)
+				delegate = dsrv.NewTracked(serverInfo, factory, handler, ops...)
+				mocks    = []*mok.Mock{
+					conn.Mock, transport.Mock, factory.Mock,
+					handler.Mock,
+				}
+				errs = make(chan error, 1)
+			)
+			go func() { errs <- delegate.Handle(context.Background(), conn) }()
+			<-handling
+			ctx, cancel := context.WithTimeout(context.Background(), delta)
+			defer cancel()
+			err := delegate.Shutdown(ctx)
+			asserterror.EqualError(t, err, wantErr)
+			<-errs
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If the delegate is drained, Handle should reject the connection with RejectShuttingDown",
+		func(t *testing.T) {
+			var (
+				wantErr = fmt.Errorf("%w: %v", dsrv.ErrRejected,
+					delegate.RejectShuttingDown)
+				conn      = cmock.NewConn()
+				transport = srvmock.NewTransport().RegisterSetSendDeadline(
+					func(deadline time.Time) (err error) { return nil },
+				).RegisterSendAdmission(
+					func(reason delegate.RejectReason) (err error) {
+						asserterror.Equal(t, reason, delegate.RejectShuttingDown)
+						return nil
+					},
+				).RegisterClose(
+					func() (err error) { return nil },
+				)
+				factory   = makeTransportFactory(conn, transport, t)
+				admission = srvmock.NewAdmission()
+				delegate  = dsrv.NewTracked(serverInfo, factory, nil,
+					append(ops, dsrv.WithAdmission(admission))...)
+				mocks = []*mok.Mock{
+					conn.Mock, transport.Mock, factory.Mock,
+					admission.Mock,
+				}
+			)
+			delegate.Drain()
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err,
+				dsrv.NewHandshakeError(dsrv.PhaseServerInfo, wantErr))
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+
+	t.Run("If the delegate is drained and the admission frame is disabled, Handle should close the connection",
+		func(t *testing.T) {
+			var (
+				wantErr = dsrv.ErrShuttingDown
+				conn    = cmock.NewConn().RegisterClose(
+					func() (err error) { return nil },
+				)
+				factory  = srvmock.NewTransportFactory()
+				delegate = dsrv.NewTracked(serverInfo, factory, nil, ops...)
+				mocks    = []*mok.Mock{conn.Mock, factory.Mock}
+			)
+			delegate.Drain()
+			err := delegate.Handle(context.Background(), conn)
+			asserterror.EqualError(t, err, wantErr)
+			asserterror.EqualDeep(t, mok.CheckCalls(mocks), mok.EmptyInfomap)
+		})
+}
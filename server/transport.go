@@ -19,7 +19,15 @@ type TransportFactory[T any] interface {
 // It is used by the delegate to receive Commands and send Results.
 type Transport[T any] interface {
 	delegate.Transport[core.Result, core.Cmd[T]]
+	SendPreamble(p delegate.Preamble) error
+	// SendAdmission sends and flushes the admission frame.
+	SendAdmission(reason delegate.RejectReason) error
+	// SendRedirect sends and flushes Redirect.
+	SendRedirect(r delegate.Redirect) error
 	SendServerInfo(info delegate.ServerInfo) error
+	SendAuthChallenge(c delegate.AuthChallenge) error
+	ReceiveAuthResponse() (r delegate.AuthResponse, err error)
+	ReceiveClientInfo() (info delegate.ClientInfo, err error)
 }
 
 // TransportHandler is a handler of the Transport.
@@ -1,12 +1,29 @@
 package delegate
 
 import (
+	"crypto/sha256"
+	"io"
+
 	muss "github.com/mus-format/mus-stream-go"
+	slops "github.com/mus-format/mus-stream-go/options/slice"
+	strops "github.com/mus-format/mus-stream-go/options/string"
 	"github.com/mus-format/mus-stream-go/ord"
 	"github.com/mus-format/mus-stream-go/raw"
+	"github.com/mus-format/mus-stream-go/varint"
 )
 
-var byteSliceMUS = ord.NewSliceSer(raw.Byte)
+var (
+	byteSliceMUS    = ord.NewSliceSer(raw.Byte)
+	stringSliceMUS  = ord.NewSliceSer(ord.String)
+	redirectAddrMUS = ord.NewValidStringSer(
+		strops.WithLenValidator(redirectAddrValidator{}))
+	authKeyIDMUS = ord.NewValidStringSer(strops.WithLenValidator(
+		maxLenValidator{max: MaxAuthKeyIDSize, err: ErrAuthKeyIDTooLong}))
+	authNonceMUS = ord.NewValidSliceSer(raw.Byte, slops.WithLenValidator[byte](
+		exactLenValidator{size: NonceSize, err: ErrInvalidNonceSize}))
+	authMACMUS = ord.NewValidSliceSer(raw.Byte, slops.WithLenValidator[byte](
+		exactLenValidator{size: sha256.Size, err: ErrInvalidMACSize}))
+)
 
 // ServerInfo allows the client to identify a compatible server.
 type ServerInfo []byte
@@ -35,3 +52,453 @@ func (s serverInfoMUS) Size(info ServerInfo) (size int) {
 func (s serverInfoMUS) Skip(r muss.Reader) (n int, err error) {
 	return byteSliceMUS.Skip(r)
 }
+
+// NewBoundedServerInfoMUS returns a ServerInfo MUS serializer, which fails
+// with ErrServerInfoTooLarge if the encoded length exceeds maxSize. The length
+// is checked before memory for ServerInfo is allocated.
+func NewBoundedServerInfoMUS(maxSize int) boundedServerInfoMUS {
+	return boundedServerInfoMUS{
+		ser: ord.NewValidSliceSer(raw.Byte,
+			slops.WithLenValidator[byte](maxSizeValidator(maxSize))),
+	}
+}
+
+type boundedServerInfoMUS struct {
+	serverInfoMUS
+	ser muss.Serializer[[]byte]
+}
+
+func (s boundedServerInfoMUS) Unmarshal(r muss.Reader) (info ServerInfo,
+	n int, err error,
+) {
+	return s.ser.Unmarshal(r)
+}
+
+type maxSizeValidator int
+
+func (v maxSizeValidator) Validate(size int) error {
+	if size > int(v) {
+		return ErrServerInfoTooLarge
+	}
+	return nil
+}
+
+// maxLenValidator fails with err if the length exceeds max.
+type maxLenValidator struct {
+	max int
+	err error
+}
+
+func (v maxLenValidator) Validate(length int) error {
+	if length > v.max {
+		return v.err
+	}
+	return nil
+}
+
+// exactLenValidator fails with err if the length differs from size.
+type exactLenValidator struct {
+	size int
+	err  error
+}
+
+func (v exactLenValidator) Validate(length int) error {
+	if length != v.size {
+		return v.err
+	}
+	return nil
+}
+
+// PreambleMUS is a Preamble MUS serializer. Preamble has a fixed size, so
+// the serializer never allocates memory based on received data.
+var PreambleMUS = preambleMUS{}
+
+type preambleMUS struct{}
+
+func (s preambleMUS) Marshal(p Preamble, w muss.Writer) (n int, err error) {
+	n, err = w.Write(p.Magic[:])
+	if err != nil {
+		return
+	}
+	if err = w.WriteByte(p.Version); err != nil {
+		return
+	}
+	return n + 1, nil
+}
+
+func (s preambleMUS) Unmarshal(r muss.Reader) (p Preamble, n int, err error) {
+	n, err = io.ReadFull(r, p.Magic[:])
+	if err != nil {
+		return
+	}
+	if p.Version, err = r.ReadByte(); err != nil {
+		return
+	}
+	n++
+	return
+}
+
+func (s preambleMUS) Size(p Preamble) (size int) {
+	return len(p.Magic) + 1
+}
+
+func (s preambleMUS) Skip(r muss.Reader) (n int, err error) {
+	_, n, err = s.Unmarshal(r)
+	return
+}
+
+// RejectReasonMUS is a RejectReason MUS serializer.
+var RejectReasonMUS = rejectReasonMUS{}
+
+type rejectReasonMUS struct{}
+
+func (s rejectReasonMUS) Marshal(r RejectReason, w muss.Writer) (n int,
+	err error,
+) {
+	if err = w.WriteByte(byte(r)); err != nil {
+		return
+	}
+	return 1, nil
+}
+
+func (s rejectReasonMUS) Unmarshal(r muss.Reader) (reason RejectReason, n int,
+	err error,
+) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return
+	}
+	return RejectReason(b), 1, nil
+}
+
+func (s rejectReasonMUS) Size(r RejectReason) (size int) {
+	return 1
+}
+
+func (s rejectReasonMUS) Skip(r muss.Reader) (n int, err error) {
+	_, n, err = s.Unmarshal(r)
+	return
+}
+
+// RedirectMUS is a Redirect MUS serializer. It fails with
+// ErrRedirectAddrTooLong if the encoded address length exceeds
+// MaxRedirectAddrSize.
+var RedirectMUS = redirectMUS{}
+
+type redirectMUS struct{}
+
+func (s redirectMUS) Marshal(r Redirect, w muss.Writer) (n int, err error) {
+	return redirectAddrMUS.Marshal(r.Addr, w)
+}
+
+func (s redirectMUS) Unmarshal(r muss.Reader) (redirect Redirect, n int,
+	err error,
+) {
+	redirect.Addr, n, err = redirectAddrMUS.Unmarshal(r)
+	return
+}
+
+func (s redirectMUS) Size(r Redirect) (size int) {
+	return redirectAddrMUS.Size(r.Addr)
+}
+
+func (s redirectMUS) Skip(r muss.Reader) (n int, err error) {
+	return redirectAddrMUS.Skip(r)
+}
+
+type redirectAddrValidator struct{}
+
+func (v redirectAddrValidator) Validate(size int) error {
+	if size > MaxRedirectAddrSize {
+		return ErrRedirectAddrTooLong
+	}
+	return nil
+}
+
+// ClientInfo allows the server to identify the client.
+type ClientInfo []byte
+
+// ClientInfoMUS is a ClientInfo MUS serializer.
+var ClientInfoMUS = clientInfoMUS{}
+
+type clientInfoMUS struct{}
+
+func (s clientInfoMUS) Marshal(info ClientInfo, w muss.Writer) (n int,
+	err error,
+) {
+	return byteSliceMUS.Marshal(info, w)
+}
+
+func (s clientInfoMUS) Unmarshal(r muss.Reader) (info ClientInfo, n int,
+	err error,
+) {
+	return byteSliceMUS.Unmarshal(r)
+}
+
+func (s clientInfoMUS) Size(info ClientInfo) (size int) {
+	return byteSliceMUS.Size(info)
+}
+
+func (s clientInfoMUS) Skip(r muss.Reader) (n int, err error) {
+	return byteSliceMUS.Skip(r)
+}
+
+// NewBoundedClientInfoMUS returns a ClientInfo MUS serializer, which fails
+// with ErrClientInfoTooLarge if the encoded length exceeds maxSize. The length
+// is checked before memory for ClientInfo is allocated.
+func NewBoundedClientInfoMUS(maxSize int) boundedClientInfoMUS {
+	return boundedClientInfoMUS{
+		ser: ord.NewValidSliceSer(raw.Byte, slops.WithLenValidator[byte](
+			maxLenValidator{max: maxSize, err: ErrClientInfoTooLarge})),
+	}
+}
+
+type boundedClientInfoMUS struct {
+	clientInfoMUS
+	ser muss.Serializer[[]byte]
+}
+
+func (s boundedClientInfoMUS) Unmarshal(r muss.Reader) (info ClientInfo,
+	n int, err error,
+) {
+	return s.ser.Unmarshal(r)
+}
+
+// AuthChallengeMUS is an AuthChallenge MUS serializer. It fails with
+// ErrInvalidNonceSize if the nonce is not NonceSize bytes long, which is
+// checked before memory for it is allocated.
+var AuthChallengeMUS = authChallengeMUS{}
+
+type authChallengeMUS struct{}
+
+func (s authChallengeMUS) Marshal(c AuthChallenge, w muss.Writer) (n int,
+	err error,
+) {
+	return authNonceMUS.Marshal(c.Nonce, w)
+}
+
+func (s authChallengeMUS) Unmarshal(r muss.Reader) (c AuthChallenge, n int,
+	err error,
+) {
+	c.Nonce, n, err = authNonceMUS.Unmarshal(r)
+	return
+}
+
+func (s authChallengeMUS) Size(c AuthChallenge) (size int) {
+	return authNonceMUS.Size(c.Nonce)
+}
+
+func (s authChallengeMUS) Skip(r muss.Reader) (n int, err error) {
+	return authNonceMUS.Skip(r)
+}
+
+// AuthResponseMUS is an AuthResponse MUS serializer. It fails with
+// ErrAuthKeyIDTooLong if the key ID exceeds MaxAuthKeyIDSize, and with
+// ErrInvalidMACSize if the MAC is not sha256.Size bytes long. Both are
+// checked before memory for them is allocated.
+var AuthResponseMUS = authResponseMUS{}
+
+type authResponseMUS struct{}
+
+func (s authResponseMUS) Marshal(resp AuthResponse, w muss.Writer) (n int,
+	err error,
+) {
+	n, err = authKeyIDMUS.Marshal(resp.KeyID, w)
+	if err != nil {
+		return
+	}
+	var n1 int
+	n1, err = authMACMUS.Marshal(resp.MAC, w)
+	n += n1
+	return
+}
+
+func (s authResponseMUS) Unmarshal(r muss.Reader) (resp AuthResponse, n int,
+	err error,
+) {
+	resp.KeyID, n, err = authKeyIDMUS.Unmarshal(r)
+	if err != nil {
+		return
+	}
+	var n1 int
+	resp.MAC, n1, err = authMACMUS.Unmarshal(r)
+	n += n1
+	return
+}
+
+func (s authResponseMUS) Size(resp AuthResponse) (size int) {
+	return authKeyIDMUS.Size(resp.KeyID) + authMACMUS.Size(resp.MAC)
+}
+
+func (s authResponseMUS) Skip(r muss.Reader) (n int, err error) {
+	n, err = authKeyIDMUS.Skip(r)
+	if err != nil {
+		return
+	}
+	var n1 int
+	n1, err = authMACMUS.Skip(r)
+	n += n1
+	return
+}
+
+// VersionMUS is a Version MUS serializer.
+var VersionMUS = versionMUS{}
+
+type versionMUS struct{}
+
+func (s versionMUS) Marshal(v Version, w muss.Writer) (n int, err error) {
+	n, err = varint.Uint64.Marshal(v.Major, w)
+	if err != nil {
+		return
+	}
+	var n1 int
+	n1, err = varint.Uint64.Marshal(v.Minor, w)
+	n += n1
+	if err != nil {
+		return
+	}
+	n1, err = varint.Uint64.Marshal(v.Patch, w)
+	n += n1
+	return
+}
+
+func (s versionMUS) Unmarshal(r muss.Reader) (v Version, n int, err error) {
+	v.Major, n, err = varint.Uint64.Unmarshal(r)
+	if err != nil {
+		return
+	}
+	var n1 int
+	v.Minor, n1, err = varint.Uint64.Unmarshal(r)
+	n += n1
+	if err != nil {
+		return
+	}
+	v.Patch, n1, err = varint.Uint64.Unmarshal(r)
+	n += n1
+	return
+}
+
+func (s versionMUS) Size(v Version) (size int) {
+	size = varint.Uint64.Size(v.Major)
+	size += varint.Uint64.Size(v.Minor)
+	return size + varint.Uint64.Size(v.Patch)
+}
+
+func (s versionMUS) Skip(r muss.Reader) (n int, err error) {
+	n, err = varint.Uint64.Skip(r)
+	if err != nil {
+		return
+	}
+	var n1 int
+	n1, err = varint.Uint64.Skip(r)
+	n += n1
+	if err != nil {
+		return
+	}
+	n1, err = varint.Uint64.Skip(r)
+	n += n1
+	return
+}
+
+// VersionedInfoMUS is a VersionedInfo MUS serializer. It does not limit
+// decoded lengths, so untrusted data should be decoded with
+// NewBoundedVersionedInfoMUS instead.
+var VersionedInfoMUS = versionedInfoMUS{}
+
+type versionedInfoMUS struct{}
+
+func (s versionedInfoMUS) Marshal(info VersionedInfo, w muss.Writer) (n int,
+	err error,
+) {
+	n, err = ord.String.Marshal(info.Service, w)
+	if err != nil {
+		return
+	}
+	var n1 int
+	n1, err = VersionMUS.Marshal(info.Version, w)
+	n += n1
+	if err != nil {
+		return
+	}
+	n1, err = stringSliceMUS.Marshal(info.Features, w)
+	n += n1
+	return
+}
+
+func (s versionedInfoMUS) Unmarshal(r muss.Reader) (info VersionedInfo,
+	n int, err error,
+) {
+	info.Service, n, err = ord.String.Unmarshal(r)
+	if err != nil {
+		return
+	}
+	var n1 int
+	info.Version, n1, err = VersionMUS.Unmarshal(r)
+	n += n1
+	if err != nil {
+		return
+	}
+	info.Features, n1, err = stringSliceMUS.Unmarshal(r)
+	n += n1
+	return
+}
+
+func (s versionedInfoMUS) Size(info VersionedInfo) (size int) {
+	size = ord.String.Size(info.Service)
+	size += VersionMUS.Size(info.Version)
+	return size + stringSliceMUS.Size(info.Features)
+}
+
+func (s versionedInfoMUS) Skip(r muss.Reader) (n int, err error) {
+	n, err = ord.String.Skip(r)
+	if err != nil {
+		return
+	}
+	var n1 int
+	n1, err = VersionMUS.Skip(r)
+	n += n1
+	if err != nil {
+		return
+	}
+	n1, err = stringSliceMUS.Skip(r)
+	n += n1
+	return
+}
+
+// NewBoundedVersionedInfoMUS returns a VersionedInfo MUS serializer, which
+// fails with ErrVersionedInfoTooLarge if the length of Service, the number of
+// Features or the length of any feature exceeds maxSize. Lengths are checked
+// before memory for them is allocated.
+func NewBoundedVersionedInfoMUS(maxSize int) boundedVersionedInfoMUS {
+	vl := maxLenValidator{max: maxSize, err: ErrVersionedInfoTooLarge}
+	str := ord.NewValidStringSer(strops.WithLenValidator(vl))
+	return boundedVersionedInfoMUS{
+		str: str,
+		strs: ord.NewValidSliceSer[string](str,
+			slops.WithLenValidator[string](vl)),
+	}
+}
+
+type boundedVersionedInfoMUS struct {
+	versionedInfoMUS
+	str  muss.Serializer[string]
+	strs muss.Serializer[[]string]
+}
+
+func (s boundedVersionedInfoMUS) Unmarshal(r muss.Reader) (
+	info VersionedInfo, n int, err error,
+) {
+	info.Service, n, err = s.str.Unmarshal(r)
+	if err != nil {
+		return
+	}
+	var n1 int
+	info.Version, n1, err = VersionMUS.Unmarshal(r)
+	n += n1
+	if err != nil {
+		return
+	}
+	info.Features, n1, err = s.strs.Unmarshal(r)
+	n += n1
+	return
+}
@@ -0,0 +1,197 @@
+package delegate
+
+import (
+	"bytes"
+	"testing"
+
+	asserterror "github.com/ymz-ncnk/assert/error"
+)
+
+func TestBoundedServerInfoMUS(t *testing.T) {
+	var (
+		info = ServerInfo("server info")
+		ser  = NewBoundedServerInfoMUS(len(info))
+		buf  = bytes.NewBuffer(nil)
+	)
+	_, err := ServerInfoMUS.Marshal(info, buf)
+	asserterror.EqualError(t, err, nil)
+
+	t.Run("ServerInfo within the limit should be decoded", func(t *testing.T) {
+		i, n, err := ser.Unmarshal(bytes.NewReader(buf.Bytes()))
+		asserterror.EqualError(t, err, nil)
+		asserterror.EqualDeep(t, i, info)
+		asserterror.Equal(t, n, ServerInfoMUS.Size(info))
+	})
+
+	t.Run("If ServerInfo exceeds the limit, Unmarshal should return ErrServerInfoTooLarge",
+		func(t *testing.T) {
+			var (
+				wantErr = ErrServerInfoTooLarge
+				ser     = NewBoundedServerInfoMUS(len(info) - 1)
+			)
+			_, _, err := ser.Unmarshal(bytes.NewReader(buf.Bytes()))
+			asserterror.EqualError(t, err, wantErr)
+		})
+}
+
+func TestPreambleMUS(t *testing.T) {
+	var (
+		wantPreamble = NewPreamble()
+		buf          = bytes.NewBuffer(nil)
+	)
+	n, err := PreambleMUS.Marshal(wantPreamble, buf)
+	asserterror.EqualError(t, err, nil)
+	asserterror.Equal(t, n, PreambleMUS.Size(wantPreamble))
+	asserterror.EqualBytes(t, buf.Bytes(), []byte{'C', 'M', 'D', 'S',
+		HandshakeVersion})
+
+	p, n, err := PreambleMUS.Unmarshal(bytes.NewReader(buf.Bytes()))
+	asserterror.EqualError(t, err, nil)
+	asserterror.Equal(t, p, wantPreamble)
+	asserterror.Equal(t, n, PreambleMUS.Size(wantPreamble))
+}
+
+func TestRejectReasonMUS(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	n, err := RejectReasonMUS.Marshal(RejectForbidden, buf)
+	asserterror.EqualError(t, err, nil)
+	asserterror.Equal(t, n, RejectReasonMUS.Size(RejectForbidden))
+
+	r, n, err := RejectReasonMUS.Unmarshal(buf)
+	asserterror.EqualError(t, err, nil)
+	asserterror.Equal(t, r, RejectForbidden)
+	asserterror.Equal(t, n, 1)
+	asserterror.Equal(t, r.String(), "forbidden")
+	asserterror.Equal(t, RejectReason(10).String(), "unknown(10)")
+}
+
+func TestRedirectMUS(t *testing.T) {
+	t.Run("Redirect should be marshalled and unmarshalled", func(t *testing.T) {
+		var (
+			wantRedirect = Redirect{Addr: "127.0.0.1:9001"}
+			buf          = bytes.NewBuffer(nil)
+		)
+		n, err := RedirectMUS.Marshal(wantRedirect, buf)
+		asserterror.EqualError(t, err, nil)
+		asserterror.Equal(t, n, RedirectMUS.Size(wantRedirect))
+
+		r, n1, err := RedirectMUS.Unmarshal(buf)
+		asserterror.EqualError(t, err, nil)
+		asserterror.Equal(t, r, wantRedirect)
+		asserterror.Equal(t, n1, n)
+	})
+
+	t.Run("If the address is too long, Unmarshal should return ErrRedirectAddrTooLong",
+		func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			_, err := RedirectMUS.Marshal(Redirect{
+				Addr: string(make([]byte, MaxRedirectAddrSize+1)),
+			}, buf)
+			asserterror.EqualError(t, err, nil)
+
+			_, _, err = RedirectMUS.Unmarshal(buf)
+			asserterror.EqualError(t, err, ErrRedirectAddrTooLong)
+		})
+}
+
+func TestAuthResponseMUS(t *testing.T) {
+	// hostileLen is a varint-encoded length close to math.MaxInt64.
+	hostileLen := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+
+	t.Run("AuthResponse should be marshalled and unmarshalled", func(t *testing.T) {
+		var (
+			wantResp = NewAuthResponse(AuthKey{ID: "key", Secret: []byte("secret")},
+				AuthChallenge{Nonce: make([]byte, NonceSize)})
+			buf = bytes.NewBuffer(nil)
+		)
+		n, err := AuthResponseMUS.Marshal(wantResp, buf)
+		asserterror.EqualError(t, err, nil)
+		asserterror.Equal(t, n, AuthResponseMUS.Size(wantResp))
+
+		resp, n1, err := AuthResponseMUS.Unmarshal(buf)
+		asserterror.EqualError(t, err, nil)
+		asserterror.EqualDeep(t, resp, wantResp)
+		asserterror.Equal(t, n1, n)
+	})
+
+	t.Run("If the key ID length is hostile, Unmarshal should return ErrAuthKeyIDTooLong",
+		func(t *testing.T) {
+			_, _, err := AuthResponseMUS.Unmarshal(bytes.NewReader(hostileLen))
+			asserterror.EqualError(t, err, ErrAuthKeyIDTooLong)
+		})
+
+	t.Run("If the MAC length is hostile, Unmarshal should return ErrInvalidMACSize",
+		func(t *testing.T) {
+			bs := append([]byte{0}, hostileLen...)
+			_, _, err := AuthResponseMUS.Unmarshal(bytes.NewReader(bs))
+			asserterror.EqualError(t, err, ErrInvalidMACSize)
+		})
+
+	t.Run("If the MAC is not a SHA-256 HMAC, Unmarshal should return ErrInvalidMACSize",
+		func(t *testing.T) {
+			var (
+				resp = AuthResponse{KeyID: "key", MAC: []byte("short")}
+				buf  = bytes.NewBuffer(nil)
+			)
+			AuthResponseMUS.Marshal(resp, buf)
+			_, _, err := AuthResponseMUS.Unmarshal(buf)
+			asserterror.EqualError(t, err, ErrInvalidMACSize)
+		})
+}
+
+func TestBoundedClientInfoMUS(t *testing.T) {
+	var (
+		info = ClientInfo("client info")
+		buf  = bytes.NewBuffer(nil)
+	)
+	_, err := ClientInfoMUS.Marshal(info, buf)
+	asserterror.EqualError(t, err, nil)
+
+	t.Run("ClientInfo within the limit should be decoded", func(t *testing.T) {
+		ser := NewBoundedClientInfoMUS(len(info))
+		i, n, err := ser.Unmarshal(bytes.NewReader(buf.Bytes()))
+		asserterror.EqualError(t, err, nil)
+		asserterror.EqualDeep(t, i, info)
+		asserterror.Equal(t, n, ClientInfoMUS.Size(info))
+	})
+
+	t.Run("If ClientInfo exceeds the limit, Unmarshal should return ErrClientInfoTooLarge",
+		func(t *testing.T) {
+			ser := NewBoundedClientInfoMUS(len(info) - 1)
+			_, _, err := ser.Unmarshal(bytes.NewReader(buf.Bytes()))
+			asserterror.EqualError(t, err, ErrClientInfoTooLarge)
+		})
+
+	t.Run("If the length is hostile, Unmarshal should return ErrClientInfoTooLarge",
+		func(t *testing.T) {
+			var (
+				ser = NewBoundedClientInfoMUS(len(info))
+				bs  = []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+			)
+			_, _, err := ser.Unmarshal(bytes.NewReader(bs))
+			asserterror.EqualError(t, err, ErrClientInfoTooLarge)
+		})
+}
+
+func TestAuthChallengeMUS(t *testing.T) {
+	t.Run("AuthChallenge should be marshalled and unmarshalled", func(t *testing.T) {
+		wantChallenge, err := NewAuthChallenge()
+		asserterror.EqualError(t, err, nil)
+		buf := bytes.NewBuffer(nil)
+		n, err := AuthChallengeMUS.Marshal(wantChallenge, buf)
+		asserterror.EqualError(t, err, nil)
+		asserterror.Equal(t, n, AuthChallengeMUS.Size(wantChallenge))
+
+		c, n1, err := AuthChallengeMUS.Unmarshal(buf)
+		asserterror.EqualError(t, err, nil)
+		asserterror.EqualDeep(t, c, wantChallenge)
+		asserterror.Equal(t, n1, n)
+	})
+
+	t.Run("If the nonce length is hostile, Unmarshal should return ErrInvalidNonceSize",
+		func(t *testing.T) {
+			bs := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+			_, _, err := AuthChallengeMUS.Unmarshal(bytes.NewReader(bs))
+			asserterror.EqualError(t, err, ErrInvalidNonceSize)
+		})
+}
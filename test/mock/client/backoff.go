@@ -0,0 +1,35 @@
+package client
+
+import (
+	"time"
+
+	"github.com/ymz-ncnk/mok"
+)
+
+type NextFn func(attempt int, prev time.Duration) time.Duration
+
+func NewBackoff() Backoff {
+	return Backoff{
+		Mock: mok.New("Backoff"),
+	}
+}
+
+type Backoff struct {
+	*mok.Mock
+}
+
+func (mock Backoff) RegisterNext(fn NextFn) Backoff {
+	mock.Register("Next", fn)
+	return mock
+}
+
+func (mock Backoff) Next(attempt int, prev time.Duration) (
+	delay time.Duration,
+) {
+	vals, err := mock.Call("Next", attempt, prev)
+	if err != nil {
+		panic(err)
+	}
+	delay = vals[0].(time.Duration)
+	return
+}
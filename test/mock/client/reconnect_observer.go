@@ -0,0 +1,91 @@
+package client
+
+import (
+	"net"
+
+	"github.com/ymz-ncnk/mok"
+)
+
+type (
+	OnDisconnectFn    func()
+	OnAttemptFn       func(attempt int)
+	OnAttemptFailedFn func(err error)
+	OnReconnectedFn   func(localAddr, remoteAddr net.Addr)
+	OnGiveUpFn        func(err error)
+)
+
+func NewReconnectObserver() ReconnectObserver {
+	return ReconnectObserver{
+		Mock: mok.New("ReconnectObserver"),
+	}
+}
+
+type ReconnectObserver struct {
+	*mok.Mock
+}
+
+func (mock ReconnectObserver) RegisterOnDisconnect(
+	fn OnDisconnectFn,
+) ReconnectObserver {
+	mock.Register("OnDisconnect", fn)
+	return mock
+}
+
+func (mock ReconnectObserver) RegisterOnAttempt(
+	fn OnAttemptFn,
+) ReconnectObserver {
+	mock.Register("OnAttempt", fn)
+	return mock
+}
+
+func (mock ReconnectObserver) RegisterOnAttemptFailed(
+	fn OnAttemptFailedFn,
+) ReconnectObserver {
+	mock.Register("OnAttemptFailed", fn)
+	return mock
+}
+
+func (mock ReconnectObserver) RegisterOnReconnected(
+	fn OnReconnectedFn,
+) ReconnectObserver {
+	mock.Register("OnReconnected", fn)
+	return mock
+}
+
+func (mock ReconnectObserver) RegisterOnGiveUp(
+	fn OnGiveUpFn,
+) ReconnectObserver {
+	mock.Register("OnGiveUp", fn)
+	return mock
+}
+
+func (mock ReconnectObserver) OnDisconnect() {
+	if _, err := mock.Call("OnDisconnect"); err != nil {
+		panic(err)
+	}
+}
+
+func (mock ReconnectObserver) OnAttempt(attempt int) {
+	if _, err := mock.Call("OnAttempt", attempt); err != nil {
+		panic(err)
+	}
+}
+
+func (mock ReconnectObserver) OnAttemptFailed(err error) {
+	if _, err := mock.Call("OnAttemptFailed", mok.SafeVal[error](err)); err != nil {
+		panic(err)
+	}
+}
+
+func (mock ReconnectObserver) OnReconnected(localAddr, remoteAddr net.Addr) {
+	if _, err := mock.Call("OnReconnected", mok.SafeVal[net.Addr](localAddr),
+		mok.SafeVal[net.Addr](remoteAddr)); err != nil {
+		panic(err)
+	}
+}
+
+func (mock ReconnectObserver) OnGiveUp(err error) {
+	if _, err := mock.Call("OnGiveUp", mok.SafeVal[error](err)); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"github.com/cmd-stream/delegate-go"
+	"github.com/ymz-ncnk/mok"
+)
+
+type MatchFn func(want, got delegate.ServerInfo) (err error)
+
+func NewServerInfoMatcher() ServerInfoMatcher {
+	return ServerInfoMatcher{
+		Mock: mok.New("ServerInfoMatcher"),
+	}
+}
+
+type ServerInfoMatcher struct {
+	*mok.Mock
+}
+
+func (mock ServerInfoMatcher) RegisterMatch(fn MatchFn) ServerInfoMatcher {
+	mock.Register("Match", fn)
+	return mock
+}
+
+func (mock ServerInfoMatcher) Match(want, got delegate.ServerInfo) (
+	err error,
+) {
+	vals, err := mock.Call("Match", want, got)
+	if err != nil {
+		panic(err)
+	}
+	err, _ = vals[0].(error)
+	return
+}
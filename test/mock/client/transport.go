@@ -10,15 +10,21 @@ import (
 )
 
 type (
-	LocalAddrFn          func() (addr net.Addr)
-	RemoteAddrFn         func() (addr net.Addr)
-	ReceiveServerInfoFn  func() (info delegate.ServerInfo, err error)
-	SetSendDeadlineFn    func(deadline time.Time) (err error)
-	SendFn               func(seq core.Seq, cmd core.Cmd[any]) (n int, err error)
-	FlushFn              func() (err error)
-	SetReceiveDeadlineFn func(deadline time.Time) (err error)
-	ReceiveFn            func() (seq core.Seq, result core.Result, n int, err error)
-	CloseFn              func() (err error)
+	LocalAddrFn            func() (addr net.Addr)
+	RemoteAddrFn           func() (addr net.Addr)
+	ReceivePreambleFn      func() (p delegate.Preamble, err error)
+	ReceiveAdmissionFn     func() (reason delegate.RejectReason, err error)
+	ReceiveRedirectFn      func() (r delegate.Redirect, err error)
+	ReceiveServerInfoFn    func(maxSize int) (info delegate.ServerInfo, err error)
+	SendClientInfoFn       func(info delegate.ClientInfo) (err error)
+	ReceiveAuthChallengeFn func() (c delegate.AuthChallenge, err error)
+	SendAuthResponseFn     func(r delegate.AuthResponse) (err error)
+	SetSendDeadlineFn      func(deadline time.Time) (err error)
+	SendFn                 func(seq core.Seq, cmd core.Cmd[any]) (n int, err error)
+	FlushFn                func() (err error)
+	SetReceiveDeadlineFn   func(deadline time.Time) (err error)
+	ReceiveFn              func() (seq core.Seq, result core.Result, n int, err error)
+	CloseFn                func() (err error)
 )
 
 func NewTransport() Transport {
@@ -41,11 +47,54 @@ func (mock Transport) RegisterRemoteAddr(fn RemoteAddrFn) Transport {
 	return mock
 }
 
+func (mock Transport) RegisterReceivePreamble(fn ReceivePreambleFn) Transport {
+	mock.Register("ReceivePreamble", fn)
+	return mock
+}
+
+func (mock Transport) RegisterReceiveAdmission(
+	fn ReceiveAdmissionFn,
+) Transport {
+	mock.Register("ReceiveAdmission", fn)
+	return mock
+}
+
+func (mock Transport) RegisterNReceiveAdmission(n int,
+	fn ReceiveAdmissionFn,
+) Transport {
+	mock.RegisterN("ReceiveAdmission", n, fn)
+	return mock
+}
+
+func (mock Transport) RegisterReceiveRedirect(fn ReceiveRedirectFn) Transport {
+	mock.Register("ReceiveRedirect", fn)
+	return mock
+}
+
 func (mock Transport) RegisterReceiveServerInfo(fn ReceiveServerInfoFn) Transport {
 	mock.Register("ReceiveServerInfo", fn)
 	return mock
 }
 
+func (mock Transport) RegisterSendClientInfo(fn SendClientInfoFn) Transport {
+	mock.Register("SendClientInfo", fn)
+	return mock
+}
+
+func (mock Transport) RegisterReceiveAuthChallenge(
+	fn ReceiveAuthChallengeFn,
+) Transport {
+	mock.Register("ReceiveAuthChallenge", fn)
+	return mock
+}
+
+func (mock Transport) RegisterSendAuthResponse(
+	fn SendAuthResponseFn,
+) Transport {
+	mock.Register("SendAuthResponse", fn)
+	return mock
+}
+
 func (mock Transport) RegisterSetSendDeadline(fn SetSendDeadlineFn) Transport {
 	mock.Register("SetSendDeadline", fn)
 	return mock
@@ -66,6 +115,13 @@ func (mock Transport) RegisterSetReceiveDeadline(fn SetReceiveDeadlineFn) Transp
 	return mock
 }
 
+func (mock Transport) RegisterNSetReceiveDeadline(n int,
+	fn SetReceiveDeadlineFn,
+) Transport {
+	mock.RegisterN("SetReceiveDeadline", n, fn)
+	return mock
+}
+
 func (mock Transport) RegisterReceive(fn ReceiveFn) Transport {
 	mock.Register("Receive", fn)
 	return mock
@@ -76,6 +132,11 @@ func (mock Transport) RegisterClose(fn CloseFn) Transport {
 	return mock
 }
 
+func (mock Transport) RegisterNClose(n int, fn CloseFn) Transport {
+	mock.RegisterN("Close", n, fn)
+	return mock
+}
+
 func (mock Transport) LocalAddr() (addr net.Addr) {
 	vals, err := mock.Call("LocalAddr")
 	if err != nil {
@@ -94,10 +155,42 @@ func (mock Transport) RemoteAddr() (addr net.Addr) {
 	return
 }
 
-func (mock Transport) ReceiveServerInfo() (info delegate.ServerInfo,
+func (mock Transport) ReceivePreamble() (p delegate.Preamble, err error) {
+	vals, err := mock.Call("ReceivePreamble")
+	if err != nil {
+		panic(err)
+	}
+	p = vals[0].(delegate.Preamble)
+	err, _ = vals[1].(error)
+	return
+}
+
+func (mock Transport) ReceiveAdmission() (reason delegate.RejectReason,
 	err error,
 ) {
-	vals, err := mock.Call("ReceiveServerInfo")
+	vals, err := mock.Call("ReceiveAdmission")
+	if err != nil {
+		panic(err)
+	}
+	reason = vals[0].(delegate.RejectReason)
+	err, _ = vals[1].(error)
+	return
+}
+
+func (mock Transport) ReceiveRedirect() (r delegate.Redirect, err error) {
+	vals, err := mock.Call("ReceiveRedirect")
+	if err != nil {
+		panic(err)
+	}
+	r = vals[0].(delegate.Redirect)
+	err, _ = vals[1].(error)
+	return
+}
+
+func (mock Transport) ReceiveServerInfo(maxSize int) (
+	info delegate.ServerInfo, err error,
+) {
+	vals, err := mock.Call("ReceiveServerInfo", maxSize)
 	if err != nil {
 		panic(err)
 	}
@@ -106,6 +199,36 @@ func (mock Transport) ReceiveServerInfo() (info delegate.ServerInfo,
 	return
 }
 
+func (mock Transport) SendClientInfo(info delegate.ClientInfo) (err error) {
+	vals, err := mock.Call("SendClientInfo", info)
+	if err != nil {
+		panic(err)
+	}
+	err, _ = vals[0].(error)
+	return
+}
+
+func (mock Transport) ReceiveAuthChallenge() (c delegate.AuthChallenge,
+	err error,
+) {
+	vals, err := mock.Call("ReceiveAuthChallenge")
+	if err != nil {
+		panic(err)
+	}
+	c = vals[0].(delegate.AuthChallenge)
+	err, _ = vals[1].(error)
+	return
+}
+
+func (mock Transport) SendAuthResponse(r delegate.AuthResponse) (err error) {
+	vals, err := mock.Call("SendAuthResponse", r)
+	if err != nil {
+		panic(err)
+	}
+	err, _ = vals[0].(error)
+	return
+}
+
 func (mock Transport) SetSendDeadline(deadline time.Time) (err error) {
 	result, err := mock.Call("SetSendDeadline", deadline)
 	if err != nil {
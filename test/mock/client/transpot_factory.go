@@ -5,7 +5,10 @@ import (
 	"github.com/ymz-ncnk/mok"
 )
 
-type NewFn func() (transport dcln.Transport[any], err error)
+type (
+	NewFn   func() (transport dcln.Transport[any], err error)
+	NewToFn func(addr string) (transport dcln.Transport[any], err error)
+)
 
 func NewTransportFactory() TransportFactory {
 	return TransportFactory{
@@ -22,6 +25,16 @@ func (mock TransportFactory) RegisterNew(fn NewFn) TransportFactory {
 	return mock
 }
 
+func (mock TransportFactory) RegisterNNew(n int, fn NewFn) TransportFactory {
+	mock.RegisterN("New", n, fn)
+	return mock
+}
+
+func (mock TransportFactory) RegisterNewTo(fn NewToFn) TransportFactory {
+	mock.Register("NewTo", fn)
+	return mock
+}
+
 func (mock TransportFactory) New() (transport dcln.Transport[any],
 	err error,
 ) {
@@ -33,3 +46,15 @@ func (mock TransportFactory) New() (transport dcln.Transport[any],
 	err, _ = vals[1].(error)
 	return
 }
+
+func (mock TransportFactory) NewTo(addr string) (
+	transport dcln.Transport[any], err error,
+) {
+	vals, err := mock.Call("NewTo", addr)
+	if err != nil {
+		panic(err)
+	}
+	transport, _ = vals[0].(dcln.Transport[any])
+	err, _ = vals[1].(error)
+	return
+}
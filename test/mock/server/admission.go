@@ -0,0 +1,49 @@
+package server
+
+import (
+	"net"
+
+	"github.com/cmd-stream/delegate-go"
+	"github.com/ymz-ncnk/mok"
+)
+
+type (
+	AdmitFn   func(conn net.Conn) (reason delegate.RejectReason)
+	ReleaseFn func(conn net.Conn)
+)
+
+func NewAdmission() Admission {
+	return Admission{
+		Mock: mok.New("Admission"),
+	}
+}
+
+type Admission struct {
+	*mok.Mock
+}
+
+func (mock Admission) RegisterAdmit(fn AdmitFn) Admission {
+	mock.Register("Admit", fn)
+	return mock
+}
+
+func (mock Admission) RegisterRelease(fn ReleaseFn) Admission {
+	mock.Register("Release", fn)
+	return mock
+}
+
+func (mock Admission) Admit(conn net.Conn) (reason delegate.RejectReason) {
+	vals, err := mock.Call("Admit", mok.SafeVal[net.Conn](conn))
+	if err != nil {
+		panic(err)
+	}
+	reason = vals[0].(delegate.RejectReason)
+	return
+}
+
+func (mock Admission) Release(conn net.Conn) {
+	_, err := mock.Call("Release", mok.SafeVal[net.Conn](conn))
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"github.com/cmd-stream/delegate-go"
+	"github.com/ymz-ncnk/mok"
+)
+
+type ValidateFn func(info delegate.ClientInfo) (err error)
+
+func NewClientInfoValidator() ClientInfoValidator {
+	return ClientInfoValidator{
+		Mock: mok.New("ClientInfoValidator"),
+	}
+}
+
+type ClientInfoValidator struct {
+	*mok.Mock
+}
+
+func (mock ClientInfoValidator) RegisterValidate(
+	fn ValidateFn,
+) ClientInfoValidator {
+	mock.Register("Validate", fn)
+	return mock
+}
+
+func (mock ClientInfoValidator) Validate(info delegate.ClientInfo) (
+	err error,
+) {
+	vals, err := mock.Call("Validate", info)
+	if err != nil {
+		panic(err)
+	}
+	err, _ = vals[0].(error)
+	return
+}
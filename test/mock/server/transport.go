@@ -10,15 +10,21 @@ import (
 )
 
 type (
-	LocalAddrFn          func() (addr net.Addr)
-	RemoteAddrFn         func() (addr net.Addr)
-	SetSendDeadlineFn    func(deadline time.Time) (err error)
-	SendFn               func(seq core.Seq, result core.Result) (n int, err error)
-	FlushFn              func() (err error)
-	SetReceiveDeadlineFn func(deadline time.Time) (err error)
-	ReceiveFn            func() (seq core.Seq, cmd core.Cmd[any], n int, err error)
-	CloseFn              func() (err error)
-	SendServerInfo       func(info delegate.ServerInfo) (err error)
+	LocalAddrFn           func() (addr net.Addr)
+	RemoteAddrFn          func() (addr net.Addr)
+	SetSendDeadlineFn     func(deadline time.Time) (err error)
+	SendFn                func(seq core.Seq, result core.Result) (n int, err error)
+	FlushFn               func() (err error)
+	SetReceiveDeadlineFn  func(deadline time.Time) (err error)
+	ReceiveFn             func() (seq core.Seq, cmd core.Cmd[any], n int, err error)
+	CloseFn               func() (err error)
+	SendPreambleFn        func(p delegate.Preamble) (err error)
+	SendAdmissionFn       func(reason delegate.RejectReason) (err error)
+	SendRedirectFn        func(r delegate.Redirect) (err error)
+	SendServerInfo        func(info delegate.ServerInfo) (err error)
+	ReceiveClientInfoFn   func() (info delegate.ClientInfo, err error)
+	SendAuthChallengeFn   func(c delegate.AuthChallenge) (err error)
+	ReceiveAuthResponseFn func() (r delegate.AuthResponse, err error)
 )
 
 func NewTransport() Transport {
@@ -95,11 +101,52 @@ func (mock Transport) RegisterReceive(fn ReceiveFn) Transport {
 	return mock
 }
 
+func (mock Transport) RegisterNReceive(n int, fn ReceiveFn) Transport {
+	mock.RegisterN("Receive", n, fn)
+	return mock
+}
+
+func (mock Transport) RegisterSendPreamble(fn SendPreambleFn) Transport {
+	mock.Register("SendPreamble", fn)
+	return mock
+}
+
+func (mock Transport) RegisterSendAdmission(fn SendAdmissionFn) Transport {
+	mock.Register("SendAdmission", fn)
+	return mock
+}
+
+func (mock Transport) RegisterSendRedirect(fn SendRedirectFn) Transport {
+	mock.Register("SendRedirect", fn)
+	return mock
+}
+
 func (mock Transport) RegisterSendServerInfo(fn SendServerInfo) Transport {
 	mock.Register("SendServerInfo", fn)
 	return mock
 }
 
+func (mock Transport) RegisterReceiveClientInfo(
+	fn ReceiveClientInfoFn,
+) Transport {
+	mock.Register("ReceiveClientInfo", fn)
+	return mock
+}
+
+func (mock Transport) RegisterSendAuthChallenge(
+	fn SendAuthChallengeFn,
+) Transport {
+	mock.Register("SendAuthChallenge", fn)
+	return mock
+}
+
+func (mock Transport) RegisterReceiveAuthResponse(
+	fn ReceiveAuthResponseFn,
+) Transport {
+	mock.Register("ReceiveAuthResponse", fn)
+	return mock
+}
+
 func (mock Transport) LocalAddr() (addr net.Addr) {
 	vals, err := mock.Call("LocalAddr")
 	if err != nil {
@@ -118,6 +165,33 @@ func (mock Transport) RemoteAddr() (addr net.Addr) {
 	return
 }
 
+func (mock Transport) SendPreamble(p delegate.Preamble) (err error) {
+	vals, err := mock.Call("SendPreamble", p)
+	if err != nil {
+		panic(err)
+	}
+	err, _ = vals[0].(error)
+	return
+}
+
+func (mock Transport) SendAdmission(reason delegate.RejectReason) (err error) {
+	vals, err := mock.Call("SendAdmission", reason)
+	if err != nil {
+		panic(err)
+	}
+	err, _ = vals[0].(error)
+	return
+}
+
+func (mock Transport) SendRedirect(r delegate.Redirect) (err error) {
+	vals, err := mock.Call("SendRedirect", r)
+	if err != nil {
+		panic(err)
+	}
+	err, _ = vals[0].(error)
+	return
+}
+
 func (mock Transport) SendServerInfo(info delegate.ServerInfo) (err error) {
 	vals, err := mock.Call("SendServerInfo", info)
 	if err != nil {
@@ -127,6 +201,39 @@ func (mock Transport) SendServerInfo(info delegate.ServerInfo) (err error) {
 	return
 }
 
+func (mock Transport) ReceiveClientInfo() (info delegate.ClientInfo,
+	err error,
+) {
+	vals, err := mock.Call("ReceiveClientInfo")
+	if err != nil {
+		panic(err)
+	}
+	info, _ = vals[0].(delegate.ClientInfo)
+	err, _ = vals[1].(error)
+	return
+}
+
+func (mock Transport) SendAuthChallenge(c delegate.AuthChallenge) (err error) {
+	vals, err := mock.Call("SendAuthChallenge", c)
+	if err != nil {
+		panic(err)
+	}
+	err, _ = vals[0].(error)
+	return
+}
+
+func (mock Transport) ReceiveAuthResponse() (r delegate.AuthResponse,
+	err error,
+) {
+	vals, err := mock.Call("ReceiveAuthResponse")
+	if err != nil {
+		panic(err)
+	}
+	r = vals[0].(delegate.AuthResponse)
+	err, _ = vals[1].(error)
+	return
+}
+
 func (mock Transport) SetSendDeadline(deadline time.Time) (err error) {
 	result, err := mock.Call("SetSendDeadline", deadline)
 	if err != nil {
@@ -24,6 +24,13 @@ func (mock TransportHandler) RegisterHandle(fn HandleFn) TransportHandler {
 	return mock
 }
 
+func (mock TransportHandler) RegisterNHandle(n int,
+	fn HandleFn,
+) TransportHandler {
+	mock.RegisterN("Handle", n, fn)
+	return mock
+}
+
 func (mock TransportHandler) Handle(ctx context.Context,
 	transport dsrv.Transport[any],
 ) (err error) {
@@ -0,0 +1,192 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"sync/atomic"
+
+	muss "github.com/mus-format/mus-stream-go"
+	"github.com/mus-format/mus-stream-go/varint"
+)
+
+// MaxChecksumFrameSize is the maximum size of a frame sent by ChecksumConn.
+// Bigger Writes are split into several frames, a received frame with a bigger
+// length is considered corrupted.
+const MaxChecksumFrameSize = 16 << 20
+
+const checksumSize = 4
+
+// ErrChecksumMismatch happens when a received frame does not match its
+// checksum. It implements net.Error, so the client treats it as a connection
+// loss and ReconnectDelegate re-establishes the connection.
+var ErrChecksumMismatch error = checksumMismatchError{}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// NewChecksumConn creates a new ChecksumConn.
+func NewChecksumConn(conn net.Conn) *ChecksumConn {
+	return &ChecksumConn{Conn: conn, r: bufio.NewReader(conn)}
+}
+
+// ChecksumConn is a net.Conn decorator, which protects data with CRC32C
+// checksums, so it can be used with any Transport built on a net.Conn.
+//
+// Each Write is sent as one or more frames: the length of the data, the data
+// itself and the checksum of both. Read verifies the checksum of the whole
+// frame before returning any data from it. If the checksum does not match,
+// the connection is closed and ErrChecksumMismatch is returned.
+type ChecksumConn struct {
+	net.Conn
+	r        *bufio.Reader
+	frame    bytes.Buffer
+	data     []byte
+	pending  []byte
+	err      error
+	mismatch atomic.Bool
+}
+
+func (c *ChecksumConn) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		chunk := p[:min(len(p), MaxChecksumFrameSize)]
+		c.frame.Reset()
+		w := &checksumWriter{w: &c.frame}
+		varint.PositiveInt.Marshal(len(chunk), w) // never fails
+		w.Write(chunk)
+		w.writeSum()
+		if _, err = c.Conn.Write(c.frame.Bytes()); err != nil {
+			return
+		}
+		n += len(chunk)
+		p = p[len(chunk):]
+	}
+	return
+}
+
+func (c *ChecksumConn) Read(p []byte) (n int, err error) {
+	for len(c.pending) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		if err = c.readFrame(); err != nil {
+			if err == ErrChecksumMismatch {
+				c.err = err
+				c.mismatch.Store(true)
+				c.Conn.Close()
+			}
+			return
+		}
+	}
+	n = copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return
+}
+
+// Close closes the underlying connection, unless it has already been closed
+// because of a checksum mismatch.
+func (c *ChecksumConn) Close() error {
+	if c.mismatch.Load() {
+		return nil
+	}
+	return c.Conn.Close()
+}
+
+// readFrame reads a frame, verifies it and stores its data in the pending
+// buffer.
+func (c *ChecksumConn) readFrame() (err error) {
+	r := &checksumReader{r: c.r}
+	length, _, err := varint.PositiveInt.Unmarshal(r)
+	if err != nil {
+		return
+	}
+	if length < 0 || length > MaxChecksumFrameSize {
+		return ErrChecksumMismatch
+	}
+	if cap(c.data) < length {
+		c.data = make([]byte, length)
+	}
+	c.data = c.data[:length]
+	if _, err = io.ReadFull(r, c.data); err != nil {
+		return
+	}
+	if _, err = r.verifySum(); err != nil {
+		return
+	}
+	c.pending = c.data
+	return
+}
+
+// checksumWriter calculates the CRC32C checksum of the written data.
+type checksumWriter struct {
+	w   muss.Writer
+	sum uint32
+}
+
+func (w *checksumWriter) WriteByte(b byte) (err error) {
+	if err = w.w.WriteByte(b); err != nil {
+		return
+	}
+	w.sum = crc32.Update(w.sum, castagnoli, []byte{b})
+	return
+}
+
+func (w *checksumWriter) Write(p []byte) (n int, err error) {
+	n, err = w.w.Write(p)
+	w.sum = crc32.Update(w.sum, castagnoli, p[:n])
+	return
+}
+
+func (w *checksumWriter) WriteString(s string) (n int, err error) {
+	return w.Write([]byte(s))
+}
+
+// writeSum writes the checksum to the underlying Writer.
+func (w *checksumWriter) writeSum() (n int, err error) {
+	var b [checksumSize]byte
+	binary.BigEndian.PutUint32(b[:], w.sum)
+	return w.w.Write(b[:])
+}
+
+// checksumReader calculates the CRC32C checksum of the read data.
+type checksumReader struct {
+	r   muss.Reader
+	sum uint32
+}
+
+func (r *checksumReader) ReadByte() (b byte, err error) {
+	if b, err = r.r.ReadByte(); err != nil {
+		return
+	}
+	r.sum = crc32.Update(r.sum, castagnoli, []byte{b})
+	return
+}
+
+func (r *checksumReader) Read(p []byte) (n int, err error) {
+	n, err = r.r.Read(p)
+	r.sum = crc32.Update(r.sum, castagnoli, p[:n])
+	return
+}
+
+// verifySum reads the checksum from the underlying Reader and compares it
+// with the calculated one.
+func (r *checksumReader) verifySum() (n int, err error) {
+	var b [checksumSize]byte
+	if n, err = io.ReadFull(r.r, b[:]); err != nil {
+		return
+	}
+	if binary.BigEndian.Uint32(b[:]) != r.sum {
+		err = ErrChecksumMismatch
+	}
+	return
+}
+
+type checksumMismatchError struct{}
+
+func (e checksumMismatchError) Error() string { return "checksum mismatch" }
+
+func (e checksumMismatchError) Timeout() bool { return false }
+
+func (e checksumMismatchError) Temporary() bool { return false }
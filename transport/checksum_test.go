@@ -0,0 +1,237 @@
+package transport_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cmd-stream/core-go"
+	ccln "github.com/cmd-stream/core-go/client"
+	"github.com/cmd-stream/delegate-go"
+	dcln "github.com/cmd-stream/delegate-go/client"
+	dsrv "github.com/cmd-stream/delegate-go/server"
+	"github.com/cmd-stream/delegate-go/transport"
+	asserterror "github.com/ymz-ncnk/assert/error"
+)
+
+func TestChecksum(t *testing.T) {
+	var (
+		serverInfo = delegate.ServerInfo("server info")
+		cmd        = BlobCmd("cmd")
+		result     = BlobResult("result")
+	)
+
+	t.Run("Values should be sent in frames with a checksum", func(t *testing.T) {
+		cln, srv, conn := recordingPipe(transport.WithChecksum())
+		defer cln.Close()
+		defer srv.Close()
+		handshake(t, cln, srv, serverInfo)
+
+		// length + seq + length + value + checksum
+		frame := record(t, cln, conn)
+		asserterror.Equal(t, len(frame), 1+1+1+len(cmd)+4)
+		go conn.Conn.Write(frame)
+		_, c, n, err := srv.Receive()
+		asserterror.EqualError(t, err, nil)
+		asserterror.Equal[core.Cmd[any]](t, c, cmd)
+		asserterror.Equal(t, n, 1+1+len(cmd))
+
+		exchange(t, cln, srv, cmd, result)
+	})
+
+	t.Run("Checksum should work together with compression and encryption",
+		func(t *testing.T) {
+			var (
+				c    = transport.CompressionGzip
+				info = delegate.VersionedInfo{
+					Service:  "service",
+					Features: []string{c.Feature()},
+				}.ServerInfo()
+				cln, srv = transport.NewPipe[any](BlobClientCodec{},
+					BlobServerCodec{}, transport.WithChecksum(),
+					transport.WithCompression(c),
+					transport.WithCompressionThreshold(1),
+					transport.WithEncryption([]byte("key")))
+			)
+			defer cln.Close()
+			defer srv.Close()
+			handshake(t, cln, srv, info)
+
+			exchange(t, cln, srv, cmd, result)
+			exchange(t, cln, srv, cmd, result)
+		})
+
+	t.Run("If a value is corrupted, Receive should return ErrChecksumMismatch and close the connection",
+		func(t *testing.T) {
+			cln, srv, conn := recordingPipe(transport.WithChecksum())
+			defer cln.Close()
+			defer srv.Close()
+			handshake(t, cln, srv, serverInfo)
+
+			frame := record(t, cln, conn)
+			frame[3] ^= 1 // length + seq + length + value
+			go conn.Conn.Write(frame)
+			_, _, _, err := srv.Receive()
+			asserterror.EqualError(t, err, transport.ErrChecksumMismatch)
+
+			_, err = conn.Conn.Write([]byte{0})
+			if err == nil {
+				t.Error("connection was not closed")
+			}
+		})
+
+	for _, c := range []struct {
+		name   string
+		length []byte
+	}{
+		{"exceeds MaxChecksumFrameSize",
+			binary.AppendUvarint(nil, transport.MaxChecksumFrameSize+1)},
+		{"overflows int", binary.AppendUvarint(nil, 1<<63)},
+	} {
+		t.Run("If the frame length "+c.name+", Read should return ErrChecksumMismatch before allocating memory",
+			func(t *testing.T) {
+				var (
+					c1, c2 = net.Pipe()
+					conn   = transport.NewChecksumConn(c2)
+				)
+				defer c1.Close()
+				go c1.Write(c.length)
+				_, err := conn.Read(make([]byte, 1))
+				asserterror.EqualError(t, err, transport.ErrChecksumMismatch)
+			})
+	}
+
+	t.Run("If the checksum does not match, the client should close without an error",
+		func(t *testing.T) {
+			var (
+				l       = listen(t)
+				factory = transport.NewClientFactory[any]("tcp", l.Addr().String(),
+					ClientCodec{}, transport.WithChecksum())
+			)
+			serveCorrupting(l, serverInfo)
+			tran, err := factory.New()
+			asserterror.EqualError(t, err, nil)
+			delegateCln, err := dcln.New(serverInfo, tran)
+			asserterror.EqualError(t, err, nil)
+			client := ccln.New(delegateCln)
+
+			result := send(t, client, Cmd(1))
+			asserterror.EqualError(t, result.Error, transport.ErrChecksumMismatch)
+			select {
+			case <-client.Done():
+			case <-time.After(time.Second):
+				t.Fatal("client was not closed")
+			}
+			asserterror.EqualError(t, client.Err(), transport.ErrChecksumMismatch)
+		})
+
+	t.Run("If the checksum does not match, ReconnectDelegate should re-establish the connection",
+		func(t *testing.T) {
+			var (
+				l       = listen(t)
+				factory = transport.NewClientFactory[any]("tcp", l.Addr().String(),
+					ClientCodec{}, transport.WithChecksum())
+			)
+			serveCorrupting(l, serverInfo)
+			delegateCln, err := dcln.NewReconnect(serverInfo, factory)
+			asserterror.EqualError(t, err, nil)
+			client := ccln.New(delegateCln)
+
+			result := send(t, client, Cmd(1))
+			asserterror.EqualError(t, result.Error, transport.ErrChecksumMismatch)
+			var netErr net.Error
+			if !errors.As(result.Error, &netErr) {
+				t.Fatal("ErrChecksumMismatch is not a net.Error")
+			}
+
+			deadline := time.Now().Add(time.Second)
+			for {
+				result = send(t, client, Cmd(2))
+				if result.Error == nil || time.Now().After(deadline) {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			asserterror.EqualError(t, result.Error, nil)
+			asserterror.Equal[core.Result](t, result.Result, Result(2))
+			asserterror.EqualError(t, client.Close(), nil)
+		})
+}
+
+// serveCorrupting serves connections accepted by l with checksums. The result
+// of Cmd(1) and everything sent after them over the first connection are
+// corrupted.
+func serveCorrupting(l net.Listener, info delegate.ServerInfo) {
+	var (
+		corrupt atomic.Bool
+		handler = dsrv.TransportHandlerFunc[any](
+			func(ctx context.Context, tran dsrv.Transport[any]) (err error) {
+				for {
+					seq, cmd, _, err := tran.Receive()
+					if err != nil {
+						return err
+					}
+					if cmd == Cmd(1) {
+						corrupt.Store(true)
+					}
+					if _, err = tran.Send(seq, Result(cmd.(Cmd))); err != nil {
+						return err
+					}
+					if err = tran.Flush(); err != nil {
+						return err
+					}
+				}
+			})
+		delegateSrv = dsrv.New(info, transport.NewServerFactory[any](ServerCodec{},
+			transport.WithChecksum()), handler)
+	)
+	go func() {
+		for first := true; ; first = false {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			if first {
+				conn = &corruptingConn{Conn: conn, corrupt: &corrupt}
+			}
+			go func() {
+				defer conn.Close()
+				delegateSrv.Handle(context.Background(), conn)
+			}()
+		}
+	}()
+}
+
+// send sends cmd and waits for its result.
+func send(t *testing.T, client *ccln.Client[any], cmd Cmd) core.AsyncResult {
+	results := make(chan core.AsyncResult, 1)
+	if _, _, err := client.Send(cmd, results); err != nil {
+		return core.AsyncResult{Error: err}
+	}
+	select {
+	case result := <-results:
+		return result
+	case <-time.After(time.Second):
+		t.Fatal("result was not received")
+		return core.AsyncResult{}
+	}
+}
+
+// corruptingConn flips the last byte of each Write while corrupt is set.
+type corruptingConn struct {
+	net.Conn
+	corrupt *atomic.Bool
+}
+
+func (c *corruptingConn) Write(p []byte) (n int, err error) {
+	if c.corrupt.Load() && len(p) > 0 {
+		p = bytes.Clone(p)
+		p[len(p)-1] ^= 1
+	}
+	return c.Conn.Write(p)
+}
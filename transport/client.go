@@ -0,0 +1,115 @@
+package transport
+
+import (
+	"net"
+
+	"github.com/cmd-stream/core-go"
+	"github.com/cmd-stream/delegate-go"
+	dcln "github.com/cmd-stream/delegate-go/client"
+	muss "github.com/mus-format/mus-stream-go"
+)
+
+// NewClient creates a new ClientTransport.
+func NewClient[T any](conn net.Conn, codec Codec[core.Cmd[T], core.Result],
+	ops ...SetOption,
+) ClientTransport[T] {
+	return ClientTransport[T]{newTransport(conn, codec, ops,
+		NewClientEncryptedConn)}
+}
+
+// ClientTransport implements the client.Transport interface.
+type ClientTransport[T any] struct {
+	*Transport[core.Cmd[T], core.Result]
+}
+
+func (t ClientTransport[T]) ReceivePreamble() (p delegate.Preamble,
+	err error,
+) {
+	p, _, err = delegate.PreambleMUS.Unmarshal(t.r)
+	return
+}
+
+func (t ClientTransport[T]) ReceiveAdmission() (
+	reason delegate.RejectReason, err error,
+) {
+	reason, _, err = delegate.RejectReasonMUS.Unmarshal(t.r)
+	return
+}
+
+func (t ClientTransport[T]) ReceiveRedirect() (r delegate.Redirect,
+	err error,
+) {
+	r, _, err = delegate.RedirectMUS.Unmarshal(t.r)
+	return
+}
+
+func (t ClientTransport[T]) ReceiveServerInfo(maxSize int) (
+	info delegate.ServerInfo, err error,
+) {
+	var ser muss.Serializer[delegate.ServerInfo] = delegate.ServerInfoMUS
+	if maxSize != 0 {
+		ser = delegate.NewBoundedServerInfoMUS(maxSize)
+	}
+	info, _, err = ser.Unmarshal(t.r)
+	if err != nil {
+		return
+	}
+	if t.comp != nil {
+		t.comp.accept(info)
+	}
+	return
+}
+
+func (t ClientTransport[T]) Receive() (seq core.Seq, result core.Result,
+	n int, err error,
+) {
+	if t.comp != nil {
+		t.comp.startReading()
+	}
+	return t.Transport.Receive()
+}
+
+func (t ClientTransport[T]) ReceiveAuthChallenge() (
+	c delegate.AuthChallenge, err error,
+) {
+	c, _, err = delegate.AuthChallengeMUS.Unmarshal(t.r)
+	return
+}
+
+func (t ClientTransport[T]) SendAuthResponse(r delegate.AuthResponse) error {
+	return send(t, t.w, delegate.AuthResponseMUS, r)
+}
+
+func (t ClientTransport[T]) SendClientInfo(info delegate.ClientInfo) error {
+	return send(t, t.w, delegate.ClientInfoMUS, info)
+}
+
+// NewClientFactory creates a new ClientFactory, which dials addr on the named
+// network.
+func NewClientFactory[T any](network, addr string,
+	codec Codec[core.Cmd[T], core.Result],
+	ops ...SetOption,
+) ClientFactory[T] {
+	return ClientFactory[T]{network: network, addr: addr, codec: codec,
+		ops: ops}
+}
+
+// ClientFactory implements the client.RedirectTransportFactory interface.
+type ClientFactory[T any] struct {
+	network string
+	addr    string
+	codec   Codec[core.Cmd[T], core.Result]
+	ops     []SetOption
+}
+
+func (f ClientFactory[T]) New() (dcln.Transport[T], error) {
+	return f.NewTo(f.addr)
+}
+
+func (f ClientFactory[T]) NewTo(addr string) (dcln.Transport[T], error) {
+	conn, err := net.Dial(f.network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn, f.codec, f.ops...), nil
+}
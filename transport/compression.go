@@ -0,0 +1,380 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+
+	"github.com/cmd-stream/core-go"
+	"github.com/cmd-stream/delegate-go"
+	dcln "github.com/cmd-stream/delegate-go/client"
+	dsrv "github.com/cmd-stream/delegate-go/server"
+	"github.com/mus-format/mus-stream-go/varint"
+)
+
+// DefaultCompressionThreshold is the default size of a Write, below which it
+// is sent uncompressed.
+const DefaultCompressionThreshold = 1024
+
+// MaxCompressedFrameSize is the maximum size of a frame sent by
+// CompressedConn, both before and after compression. Bigger Writes are split
+// into several frames, a received frame with a bigger size is rejected.
+const MaxCompressedFrameSize = 16 << 20
+
+// compressionAck is sent by the client, followed by the Compression, to accept
+// the compression offered by the server. The Transports of this package never
+// send it first: a value starts with a non-negative core.Seq, and a handshake
+// message would have to be longer than DefaultMaxClientInfoSize.
+var compressionAck = append([]byte{0xff}, delegate.Magic[:]...)
+
+var (
+	// ErrUnsupportedCompression happens when a peer uses a compression
+	// algorithm that was not offered.
+	ErrUnsupportedCompression = errors.New("unsupported compression")
+
+	// ErrCompressedFrameTooLarge happens when a received frame exceeds
+	// MaxCompressedFrameSize before or after decompression.
+	ErrCompressedFrameTooLarge = errors.New("compressed frame too large")
+)
+
+// Compression is a compression algorithm.
+type Compression byte
+
+const (
+	CompressionNone Compression = iota
+	CompressionFlate
+	CompressionGzip
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionFlate:
+		return "flate"
+	case CompressionGzip:
+		return "gzip"
+	default:
+		return "unknown"
+	}
+}
+
+// Feature returns the delegate.VersionedInfo feature with which the server
+// offers the compression.
+func (c Compression) Feature() string {
+	return "compress/" + c.String()
+}
+
+func offered(c Compression, info delegate.ServerInfo) bool {
+	i, err := delegate.ParseVersionedInfo(info)
+	if err != nil {
+		return false
+	}
+	return i.HasFeatures([]string{c.Feature()})
+}
+
+// NewCompressedConn creates a new CompressedConn.
+func NewCompressedConn(conn net.Conn, c Compression,
+	threshold int,
+) *CompressedConn {
+	return &CompressedConn{Conn: conn, c: c, threshold: threshold,
+		r: bufio.NewReader(conn)}
+}
+
+// CompressedConn is a net.Conn decorator, which compresses data with
+// compress/flate or compress/gzip. It is negotiated during the handshake, so
+// the Transport built on it must be wrapped with NewCompressedClient or
+// NewCompressedServer.
+//
+// The server offers compression by including Compression.Feature() in the
+// features of its delegate.VersionedInfo. The client accepts it, if it uses
+// the same algorithm, by sending an acknowledgment before the data of its
+// first Write after ServerInfo. From then on, each Write in both directions
+// is sent as one or more frames: the Compression, the length of the data and
+// the data itself. Writes smaller than the threshold are sent with
+// CompressionNone. Old peers never offer or accept compression, so they keep
+// exchanging uncompressed data.
+type CompressedConn struct {
+	net.Conn
+	c         Compression
+	threshold int
+	r         *bufio.Reader
+
+	accepted    atomic.Bool
+	readFrames  atomic.Bool
+	writeFrames atomic.Bool
+	ack         bool
+	offered     bool
+	probe       bool
+
+	frame bytes.Buffer
+	zbuf  bytes.Buffer
+	zw    interface {
+		io.WriteCloser
+		Reset(w io.Writer)
+	}
+
+	data    []byte
+	plain   bytes.Buffer
+	pending []byte
+	fr      io.ReadCloser
+	gr      *gzip.Reader
+}
+
+func (c *CompressedConn) Write(p []byte) (n int, err error) {
+	if !c.writeFrames.Load() {
+		return c.Conn.Write(p)
+	}
+	for len(p) > 0 {
+		chunk := p[:min(len(p), MaxCompressedFrameSize)]
+		c.frame.Reset()
+		if c.ack {
+			c.frame.Write(compressionAck)
+			c.frame.WriteByte(byte(c.c))
+			c.ack = false
+		}
+		if err = c.writeFrame(chunk); err != nil {
+			return
+		}
+		if _, err = c.Conn.Write(c.frame.Bytes()); err != nil {
+			return
+		}
+		n += len(chunk)
+		p = p[len(chunk):]
+	}
+	return
+}
+
+func (c *CompressedConn) Read(p []byte) (n int, err error) {
+	if c.probe {
+		if err = c.receiveAck(); err != nil {
+			return
+		}
+		c.probe = false
+	}
+	if !c.readFrames.Load() {
+		return c.r.Read(p)
+	}
+	for len(c.pending) == 0 {
+		if err = c.readFrame(); err != nil {
+			return
+		}
+	}
+	n = copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return
+}
+
+// offer is called by the server after ServerInfo. The next Read checks
+// whether the client accepted the compression.
+func (c *CompressedConn) offer(info delegate.ServerInfo) {
+	c.offered = offered(c.c, info)
+	c.probe = true
+}
+
+// accept is called by the client after ServerInfo. If the server offers the
+// compression, the acknowledgment is sent with the next Write, which is
+// compressed, like all subsequent ones.
+func (c *CompressedConn) accept(info delegate.ServerInfo) {
+	if offered(c.c, info) {
+		c.accepted.Store(true)
+		c.ack = true
+		c.writeFrames.Store(true)
+	}
+}
+
+// startReading is called by the client before receiving the first value.
+// The server may send uncompressed handshake messages after ServerInfo, but
+// all values are sent after the acknowledgment was received.
+func (c *CompressedConn) startReading() {
+	if c.accepted.Load() {
+		c.readFrames.Store(true)
+	}
+}
+
+// receiveAck checks whether the received data starts with the
+// acknowledgment and, if so, enables compression.
+func (c *CompressedConn) receiveAck() (err error) {
+	var b []byte
+	for i := range compressionAck {
+		if b, err = c.r.Peek(i + 1); err != nil {
+			return
+		}
+		if b[i] != compressionAck[i] {
+			return
+		}
+	}
+	if b, err = c.r.Peek(len(compressionAck) + 1); err != nil {
+		return
+	}
+	if !c.offered || Compression(b[len(compressionAck)]) != c.c {
+		return ErrUnsupportedCompression
+	}
+	c.r.Discard(len(b)) // never fails, the data is buffered
+	c.readFrames.Store(true)
+	c.writeFrames.Store(true)
+	return
+}
+
+// writeFrame appends the frame with p to the frame buffer.
+func (c *CompressedConn) writeFrame(p []byte) (err error) {
+	if len(p) >= c.threshold {
+		if err = c.compress(p); err != nil {
+			return
+		}
+		if c.zbuf.Len() < len(p) {
+			c.frame.WriteByte(byte(c.c))
+			varint.PositiveInt.Marshal(c.zbuf.Len(), &c.frame) // never fails
+			c.frame.Write(c.zbuf.Bytes())
+			return
+		}
+	}
+	c.frame.WriteByte(byte(CompressionNone))
+	varint.PositiveInt.Marshal(len(p), &c.frame) // never fails
+	c.frame.Write(p)
+	return
+}
+
+func (c *CompressedConn) compress(p []byte) (err error) {
+	c.zbuf.Reset()
+	if c.zw == nil {
+		switch c.c {
+		case CompressionFlate:
+			c.zw, _ = flate.NewWriter(&c.zbuf, flate.DefaultCompression) // never fails with a valid level
+		case CompressionGzip:
+			c.zw = gzip.NewWriter(&c.zbuf)
+		default:
+			return ErrUnsupportedCompression
+		}
+	} else {
+		c.zw.Reset(&c.zbuf)
+	}
+	if _, err = c.zw.Write(p); err != nil {
+		return
+	}
+	return c.zw.Close()
+}
+
+// readFrame reads a frame and stores its decompressed data in the pending
+// buffer.
+func (c *CompressedConn) readFrame() (err error) {
+	b, err := c.r.ReadByte()
+	if err != nil {
+		return
+	}
+	if Compression(b) != CompressionNone && Compression(b) != c.c {
+		return ErrUnsupportedCompression
+	}
+	length, _, err := varint.PositiveInt.Unmarshal(c.r)
+	if err != nil {
+		return
+	}
+	if length < 0 || length > MaxCompressedFrameSize {
+		return ErrCompressedFrameTooLarge
+	}
+	if cap(c.data) < length {
+		c.data = make([]byte, length)
+	}
+	c.data = c.data[:length]
+	if _, err = io.ReadFull(c.r, c.data); err != nil {
+		return
+	}
+	if Compression(b) == CompressionNone {
+		c.pending = c.data
+		return
+	}
+	return c.decompress()
+}
+
+func (c *CompressedConn) decompress() (err error) {
+	zr, err := c.reader(bytes.NewReader(c.data))
+	if err != nil {
+		return
+	}
+	c.plain.Reset()
+	n, err := c.plain.ReadFrom(io.LimitReader(zr, MaxCompressedFrameSize+1))
+	if err != nil {
+		return
+	}
+	if n > MaxCompressedFrameSize {
+		return ErrCompressedFrameTooLarge
+	}
+	c.pending = c.plain.Bytes()
+	return
+}
+
+func (c *CompressedConn) reader(r io.Reader) (zr io.Reader, err error) {
+	switch c.c {
+	case CompressionFlate:
+		if c.fr == nil {
+			c.fr = flate.NewReader(r)
+			return c.fr, nil
+		}
+		return c.fr, c.fr.(flate.Resetter).Reset(r, nil)
+	case CompressionGzip:
+		if c.gr == nil {
+			c.gr, err = gzip.NewReader(r)
+			return c.gr, err
+		}
+		return c.gr, c.gr.Reset(r)
+	default:
+		return nil, ErrUnsupportedCompression
+	}
+}
+
+// NewCompressedClient wraps a client Transport built on conn, so that conn
+// accepts the compression offered in ServerInfo.
+func NewCompressedClient[T any](transport dcln.Transport[T],
+	conn *CompressedConn,
+) CompressedClientTransport[T] {
+	return CompressedClientTransport[T]{Transport: transport, conn: conn}
+}
+
+// CompressedClientTransport implements the client.Transport interface.
+type CompressedClientTransport[T any] struct {
+	dcln.Transport[T]
+	conn *CompressedConn
+}
+
+func (t CompressedClientTransport[T]) ReceiveServerInfo(maxSize int) (
+	info delegate.ServerInfo, err error,
+) {
+	if info, err = t.Transport.ReceiveServerInfo(maxSize); err != nil {
+		return
+	}
+	t.conn.accept(info)
+	return
+}
+
+func (t CompressedClientTransport[T]) Receive() (seq core.Seq,
+	result core.Result, n int, err error,
+) {
+	t.conn.startReading()
+	return t.Transport.Receive()
+}
+
+// NewCompressedServer wraps a server Transport built on conn, so that conn
+// offers the compression if its feature is included in ServerInfo.
+func NewCompressedServer[T any](transport dsrv.Transport[T],
+	conn *CompressedConn,
+) CompressedServerTransport[T] {
+	return CompressedServerTransport[T]{Transport: transport, conn: conn}
+}
+
+// CompressedServerTransport implements the server.Transport interface.
+type CompressedServerTransport[T any] struct {
+	dsrv.Transport[T]
+	conn *CompressedConn
+}
+
+func (t CompressedServerTransport[T]) SendServerInfo(
+	info delegate.ServerInfo,
+) error {
+	t.conn.offer(info)
+	return t.Transport.SendServerInfo(info)
+}
@@ -0,0 +1,401 @@
+package transport_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cmd-stream/core-go"
+	ccln "github.com/cmd-stream/core-go/client"
+	"github.com/cmd-stream/delegate-go"
+	dcln "github.com/cmd-stream/delegate-go/client"
+	dsrv "github.com/cmd-stream/delegate-go/server"
+	"github.com/cmd-stream/delegate-go/transport"
+	muss "github.com/mus-format/mus-stream-go"
+	"github.com/mus-format/mus-stream-go/ord"
+	asserterror "github.com/ymz-ncnk/assert/error"
+)
+
+func TestCompression(t *testing.T) {
+	var (
+		bigCmd      = BlobCmd(strings.Repeat("cmd", 1000))
+		bigResult   = BlobResult(strings.Repeat("result", 1000))
+		smallCmd    = BlobCmd("cmd")
+		smallResult = BlobResult("result")
+		offerInfo   = func(c transport.Compression) delegate.ServerInfo {
+			return delegate.VersionedInfo{
+				Service:  "service",
+				Features: []string{c.Feature()},
+			}.ServerInfo()
+		}
+	)
+
+	for _, c := range []transport.Compression{
+		transport.CompressionFlate,
+		transport.CompressionGzip,
+	} {
+		t.Run("If both peers use "+c.String()+" compression, big values should be compressed",
+			func(t *testing.T) {
+				op := transport.WithCompression(c)
+				cln, srv, clnConn, srvConn := countingPipe(
+					[]transport.SetOption{op}, []transport.SetOption{op})
+				defer cln.Close()
+				defer srv.Close()
+				handshake(t, cln, srv, offerInfo(c))
+				clnConn.count() // skip ServerInfo
+
+				exchange(t, cln, srv, bigCmd, bigResult)
+				if n := srvConn.count(); n >= len(bigCmd) {
+					t.Errorf("Cmd was not compressed, %v bytes received", n)
+				}
+				if n := clnConn.count(); n >= len(bigResult) {
+					t.Errorf("Result was not compressed, %v bytes received", n)
+				}
+				exchange(t, cln, srv, bigCmd, bigResult)
+			})
+	}
+
+	t.Run("Writes below the threshold should be sent uncompressed",
+		func(t *testing.T) {
+			var (
+				c  = transport.CompressionFlate
+				op = []transport.SetOption{transport.WithCompression(c),
+					transport.WithCompressionThreshold(len(bigCmd) + 10)}
+				cln, srv, clnConn, srvConn = countingPipe(op, op)
+			)
+			defer cln.Close()
+			defer srv.Close()
+			handshake(t, cln, srv, offerInfo(c))
+			clnConn.count() // skip ServerInfo
+
+			cmdN, resultN := exchange(t, cln, srv, smallCmd, smallResult)
+			// ack + Compression + Compression + length + data
+			asserterror.Equal(t, srvConn.count(), 5+1+1+1+cmdN)
+			// Compression + length + data
+			asserterror.Equal(t, clnConn.count(), 1+1+resultN)
+
+			cmdN, _ = exchange(t, cln, srv, bigCmd, smallResult)
+			asserterror.Equal(t, srvConn.count(), 1+2+cmdN)
+		})
+
+	t.Run("If the server does not offer compression, data should be sent uncompressed",
+		func(t *testing.T) {
+			op := transport.WithCompression(transport.CompressionFlate)
+			cln, srv, clnConn, srvConn := countingPipe(
+				[]transport.SetOption{op}, []transport.SetOption{op})
+			defer cln.Close()
+			defer srv.Close()
+			handshake(t, cln, srv, offerInfo(transport.CompressionGzip))
+			clnConn.count() // skip ServerInfo
+
+			cmdN, resultN := exchange(t, cln, srv, bigCmd, bigResult)
+			asserterror.Equal(t, srvConn.count(), cmdN)
+			asserterror.Equal(t, clnConn.count(), resultN)
+		})
+
+	t.Run("Old client should work with the server that offers compression",
+		func(t *testing.T) {
+			c := transport.CompressionFlate
+			cln, srv, clnConn, srvConn := countingPipe(nil,
+				[]transport.SetOption{transport.WithCompression(c)})
+			defer cln.Close()
+			defer srv.Close()
+			handshake(t, cln, srv, offerInfo(c))
+			clnConn.count() // skip ServerInfo
+
+			cmdN, resultN := exchange(t, cln, srv, bigCmd, bigResult)
+			asserterror.Equal(t, srvConn.count(), cmdN)
+			asserterror.Equal(t, clnConn.count(), resultN)
+		})
+
+	t.Run("If the client accepts compression that was not offered, Receive should return ErrUnsupportedCompression",
+		func(t *testing.T) {
+			var (
+				c        = transport.CompressionFlate
+				cln, srv = pipe(
+					[]transport.SetOption{transport.WithCompression(c)},
+					[]transport.SetOption{
+						transport.WithCompression(transport.CompressionGzip),
+					})
+				errs = make(chan error, 1)
+			)
+			defer cln.Close()
+			defer srv.Close()
+			handshake(t, cln, srv, offerInfo(c))
+
+			go func() {
+				_, err := cln.Send(1, smallCmd)
+				if err == nil {
+					err = cln.Flush()
+				}
+				errs <- err
+			}()
+			_, _, _, err := srv.Receive()
+			asserterror.EqualError(t, err, transport.ErrUnsupportedCompression)
+			srv.Close()
+			<-errs
+		})
+
+	t.Run("CompressedConn should work with any Transport built on a net.Conn",
+		func(t *testing.T) {
+			var (
+				c      = transport.CompressionGzip
+				c1, c2 = net.Pipe()
+				cc     = transport.NewCompressedConn(c1, c, 1)
+				sc     = transport.NewCompressedConn(c2, c, 1)
+				cln    = transport.NewCompressedClient[any](
+					transport.NewClient[any](cc, BlobClientCodec{}), cc)
+				srv = transport.NewCompressedServer[any](
+					transport.NewServer[any](sc, BlobServerCodec{}), sc)
+			)
+			defer cln.Close()
+			defer srv.Close()
+			handshake(t, cln, srv, offerInfo(c))
+
+			exchange(t, cln, srv, bigCmd, bigResult)
+			exchange(t, cln, srv, smallCmd, smallResult)
+		})
+
+	t.Run("Handshake messages sent by the server after ServerInfo should not be compressed",
+		func(t *testing.T) {
+			var (
+				c   = transport.CompressionFlate
+				op  = transport.WithCompression(c)
+				key = delegate.AuthKey{ID: "1", Secret: []byte("secret")}
+				l   = listen(t)
+			)
+			go func() {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				dsrv.New(offerInfo(c), transport.NewServerFactory[any](
+					BlobServerCodec{}, op), echoHandler{},
+					dsrv.WithAuthKeys(key),
+				).Handle(context.Background(), conn)
+			}()
+			tran, err := transport.NewClientFactory[any]("tcp",
+				l.Addr().String(), BlobClientCodec{}, op).New()
+			asserterror.EqualError(t, err, nil)
+			delegateCln, err := dcln.New(offerInfo(c), tran, dcln.WithAuthKey(key))
+			asserterror.EqualError(t, err, nil)
+			client := ccln.New(delegateCln)
+			defer client.Close()
+
+			results := make(chan core.AsyncResult, 1)
+			_, _, err = client.Send(bigCmd, results)
+			asserterror.EqualError(t, err, nil)
+			select {
+			case result := <-results:
+				asserterror.EqualError(t, result.Error, nil)
+				asserterror.Equal[core.Result](t, result.Result,
+					BlobResult(bigCmd))
+			case <-time.After(time.Second):
+				t.Fatal("result was not received")
+			}
+		})
+
+	for _, c := range []struct {
+		name  string
+		frame []byte
+	}{
+		{"length exceeds MaxCompressedFrameSize", binary.AppendUvarint(
+			[]byte{byte(transport.CompressionFlate)},
+			transport.MaxCompressedFrameSize+1)},
+		{"length overflows int", binary.AppendUvarint(
+			[]byte{byte(transport.CompressionFlate)}, 1<<63)},
+		{"decompressed data exceeds MaxCompressedFrameSize",
+			flateFrame(t, make([]byte, transport.MaxCompressedFrameSize+1))},
+	} {
+		t.Run("If the frame "+c.name+", Receive should return ErrCompressedFrameTooLarge",
+			func(t *testing.T) {
+				var (
+					comp   = transport.CompressionFlate
+					c1, c2 = net.Pipe()
+					srv    = transport.NewServer[any](c2, BlobServerCodec{},
+						transport.WithCompression(comp))
+				)
+				defer c1.Close()
+				defer srv.Close()
+				go func() {
+					delegate.ServerInfoMUS.Unmarshal(bufio.NewReader(c1))
+					ack := append([]byte{0xff}, delegate.Magic[:]...)
+					c1.Write(append(append(ack, byte(comp)), c.frame...))
+				}()
+				err := srv.SendServerInfo(offerInfo(comp))
+				asserterror.EqualError(t, err, nil)
+				_, _, _, err = srv.Receive()
+				asserterror.EqualError(t, err, transport.ErrCompressedFrameTooLarge)
+			})
+	}
+}
+
+// flateFrame returns a CompressedConn frame with p compressed by flate.
+func flateFrame(t *testing.T, p []byte) []byte {
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.BestCompression)
+	if _, err := w.Write(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	frame := binary.AppendUvarint([]byte{byte(transport.CompressionFlate)},
+		uint64(buf.Len()))
+	return append(frame, buf.Bytes()...)
+}
+
+// echoHandler sends each received BlobCmd back as a BlobResult.
+type echoHandler struct{}
+
+func (h echoHandler) Handle(ctx context.Context,
+	tran dsrv.Transport[any],
+) (err error) {
+	for {
+		seq, cmd, _, err := tran.Receive()
+		if err != nil {
+			return err
+		}
+		if _, err = tran.Send(seq, BlobResult(cmd.(BlobCmd))); err != nil {
+			return err
+		}
+		if err = tran.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// countingPipe creates a client and server Transport, whose connections
+// count the received bytes.
+func countingPipe(clnOps, srvOps []transport.SetOption) (
+	cln transport.ClientTransport[any], srv transport.ServerTransport[any],
+	clnConn, srvConn *countingConn,
+) {
+	c1, c2 := net.Pipe()
+	clnConn = &countingConn{Conn: c1}
+	srvConn = &countingConn{Conn: c2}
+	cln = transport.NewClient[any](clnConn, BlobClientCodec{}, clnOps...)
+	srv = transport.NewServer[any](srvConn, BlobServerCodec{}, srvOps...)
+	return
+}
+
+// countingConn counts the received bytes.
+type countingConn struct {
+	net.Conn
+	n atomic.Int64
+}
+
+func (c *countingConn) Read(b []byte) (n int, err error) {
+	n, err = c.Conn.Read(b)
+	c.n.Add(int64(n))
+	return
+}
+
+// count returns the number of bytes received since the previous call.
+func (c *countingConn) count() int {
+	return int(c.n.Swap(0))
+}
+
+func pipe(clnOps, srvOps []transport.SetOption) (
+	cln transport.ClientTransport[any], srv transport.ServerTransport[any],
+) {
+	c1, c2 := net.Pipe()
+	cln = transport.NewClient[any](c1, BlobClientCodec{}, clnOps...)
+	srv = transport.NewServer[any](c2, BlobServerCodec{}, srvOps...)
+	return
+}
+
+func handshake(t *testing.T, cln dcln.Transport[any],
+	srv dsrv.Transport[any],
+	info delegate.ServerInfo,
+) {
+	errs := make(chan error, 1)
+	go func() { errs <- srv.SendServerInfo(info) }()
+	_, err := cln.ReceiveServerInfo(0)
+	asserterror.EqualError(t, err, nil)
+	asserterror.EqualError(t, <-errs, nil)
+}
+
+func exchange(t *testing.T, cln dcln.Transport[any],
+	srv dsrv.Transport[any],
+	cmd BlobCmd,
+	result BlobResult,
+) (cmdN, resultN int) {
+	errs := make(chan error, 1)
+	go func() {
+		_, err := cln.Send(1, cmd)
+		if err == nil {
+			err = cln.Flush()
+		}
+		errs <- err
+	}()
+	seq, c, cmdN, err := srv.Receive()
+	asserterror.EqualError(t, err, nil)
+	asserterror.Equal(t, seq, core.Seq(1))
+	asserterror.Equal[core.Cmd[any]](t, c, cmd)
+	asserterror.EqualError(t, <-errs, nil)
+
+	go func() {
+		_, err := srv.Send(1, result)
+		if err == nil {
+			err = srv.Flush()
+		}
+		errs <- err
+	}()
+	seq, r, resultN, err := cln.Receive()
+	asserterror.EqualError(t, err, nil)
+	asserterror.Equal(t, seq, core.Seq(1))
+	asserterror.Equal[core.Result](t, r, result)
+	asserterror.EqualError(t, <-errs, nil)
+	return
+}
+
+type BlobCmd string
+
+func (c BlobCmd) Exec(ctx context.Context, seq core.Seq, at time.Time,
+	receiver any, proxy core.Proxy,
+) error {
+	return nil
+}
+
+type BlobResult string
+
+func (r BlobResult) LastOne() bool { return true }
+
+type BlobClientCodec struct{}
+
+func (c BlobClientCodec) Encode(cmd core.Cmd[any], w muss.Writer) (n int,
+	err error,
+) {
+	return ord.String.Marshal(string(cmd.(BlobCmd)), w)
+}
+
+func (c BlobClientCodec) Decode(r muss.Reader) (result core.Result, n int,
+	err error,
+) {
+	s, n, err := ord.String.Unmarshal(r)
+	return BlobResult(s), n, err
+}
+
+type BlobServerCodec struct{}
+
+func (c BlobServerCodec) Encode(result core.Result, w muss.Writer) (n int,
+	err error,
+) {
+	return ord.String.Marshal(string(result.(BlobResult)), w)
+}
+
+func (c BlobServerCodec) Decode(r muss.Reader) (cmd core.Cmd[any], n int,
+	err error,
+) {
+	s, n, err := ord.String.Unmarshal(r)
+	return BlobCmd(s), n, err
+}
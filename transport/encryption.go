@@ -0,0 +1,296 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/mus-format/mus-stream-go/varint"
+)
+
+// MaxSealedFrameSize is the maximum size of a sealed frame. Bigger frames are
+// rejected before memory for them is allocated.
+const MaxSealedFrameSize = 16 << 20
+
+// DefaultKeyExchangeTimeout is the default time within which the peer must
+// send its part of the key exchange.
+const DefaultKeyExchangeTimeout = 10 * time.Second
+
+const (
+	saltSize       = 32
+	counterSize    = 8
+	clientKeyLabel = "cmd-stream client key"
+	serverKeyLabel = "cmd-stream server key"
+)
+
+var (
+	// ErrReplayedFrame happens when a received frame was already received
+	// before.
+	ErrReplayedFrame = errors.New("replayed frame")
+
+	// ErrTamperedFrame happens when a received frame can't be authenticated,
+	// for example, because it was modified or the peer uses another key.
+	ErrTamperedFrame = errors.New("tampered frame")
+
+	// ErrSealedFrameTooLarge happens when a received frame exceeds
+	// MaxSealedFrameSize.
+	ErrSealedFrameTooLarge = errors.New("sealed frame too large")
+)
+
+// NewClientEncryptedConn creates a new EncryptedConn for the client side of
+// the connection.
+func NewClientEncryptedConn(conn net.Conn, key []byte,
+	timeout time.Duration,
+) *EncryptedConn {
+	return newEncryptedConn(conn, key, timeout, false)
+}
+
+// NewServerEncryptedConn creates a new EncryptedConn for the server side of
+// the connection.
+func NewServerEncryptedConn(conn net.Conn, key []byte,
+	timeout time.Duration,
+) *EncryptedConn {
+	return newEncryptedConn(conn, key, timeout, true)
+}
+
+func newEncryptedConn(conn net.Conn, key []byte, timeout time.Duration,
+	server bool,
+) *EncryptedConn {
+	return &EncryptedConn{
+		Conn:    conn,
+		key:     key,
+		timeout: timeout,
+		server:  server,
+		r:       bufio.NewReader(conn),
+	}
+}
+
+// EncryptedConn is a net.Conn decorator, which seals all sent data with
+// AES-GCM and rejects received data that was replayed or tampered with, so it
+// can be used with any Transport built on a net.Conn.
+//
+// Before the first Read or Write, peers exchange random salts and derive
+// session keys from them and the pre-shared key, see Handshake. Each Write is
+// then sent as one or more sealed frames: an 8-byte counter, from which the
+// nonce is made, followed by the length of the ciphertext and the ciphertext
+// itself. Counters of each direction start from 1 and must strictly increase.
+type EncryptedConn struct {
+	net.Conn
+	key     []byte
+	timeout time.Duration
+	server  bool
+	r       *bufio.Reader
+
+	hmu  sync.Mutex
+	done bool
+	err  error
+
+	dmu          sync.Mutex
+	readDeadline time.Time
+
+	send        cipher.AEAD
+	sendCounter uint64
+	frame       bytes.Buffer
+	sealed      []byte
+
+	recv        cipher.AEAD
+	recvCounter uint64
+	ciphertext  []byte
+	opened      []byte
+	plain       []byte
+}
+
+// Handshake exchanges salts with the peer and derives session keys. The
+// server sends its salt first, then both peers wait for the other's one at
+// most timeout, if it is not 0.
+//
+// It is called automatically by the first Read or Write, subsequent calls
+// return the result of the first one.
+func (c *EncryptedConn) Handshake() error {
+	c.hmu.Lock()
+	defer c.hmu.Unlock()
+	if !c.done {
+		c.err = c.exchange()
+		c.done = true
+	}
+	return c.err
+}
+
+func (c *EncryptedConn) Write(p []byte) (n int, err error) {
+	if err = c.Handshake(); err != nil {
+		return
+	}
+	maxSize := MaxSealedFrameSize - c.send.Overhead()
+	for len(p) > 0 {
+		chunk := p[:min(len(p), maxSize)]
+		if err = c.seal(chunk); err != nil {
+			return
+		}
+		n += len(chunk)
+		p = p[len(chunk):]
+	}
+	return
+}
+
+func (c *EncryptedConn) Read(p []byte) (n int, err error) {
+	if err = c.Handshake(); err != nil {
+		return
+	}
+	for len(c.plain) == 0 {
+		if err = c.open(); err != nil {
+			return
+		}
+	}
+	n = copy(p, c.plain)
+	c.plain = c.plain[n:]
+	return
+}
+
+func (c *EncryptedConn) SetDeadline(deadline time.Time) (err error) {
+	if err = c.Conn.SetDeadline(deadline); err != nil {
+		return
+	}
+	c.setReadDeadline(deadline)
+	return
+}
+
+func (c *EncryptedConn) SetReadDeadline(deadline time.Time) (err error) {
+	if err = c.Conn.SetReadDeadline(deadline); err != nil {
+		return
+	}
+	c.setReadDeadline(deadline)
+	return
+}
+
+func (c *EncryptedConn) setReadDeadline(deadline time.Time) {
+	c.dmu.Lock()
+	c.readDeadline = deadline
+	c.dmu.Unlock()
+}
+
+func (c *EncryptedConn) exchange() (err error) {
+	var (
+		salt     = make([]byte, saltSize)
+		peerSalt = make([]byte, saltSize)
+	)
+	if _, err = rand.Read(salt); err != nil {
+		return
+	}
+	if c.server {
+		if _, err = c.Conn.Write(salt); err != nil {
+			return
+		}
+		if err = c.readSalt(peerSalt); err != nil {
+			return
+		}
+		c.send = c.aead(serverKeyLabel, salt, peerSalt)
+		c.recv = c.aead(clientKeyLabel, salt, peerSalt)
+		return
+	}
+	if err = c.readSalt(peerSalt); err != nil {
+		return
+	}
+	if _, err = c.Conn.Write(salt); err != nil {
+		return
+	}
+	c.send = c.aead(clientKeyLabel, peerSalt, salt)
+	c.recv = c.aead(serverKeyLabel, peerSalt, salt)
+	return
+}
+
+// readSalt reads the peer's salt within the timeout and then restores the
+// read deadline set by the user.
+func (c *EncryptedConn) readSalt(salt []byte) (err error) {
+	if c.timeout != 0 {
+		if err = c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+			return
+		}
+		defer func() {
+			c.dmu.Lock()
+			deadline := c.readDeadline
+			c.dmu.Unlock()
+			if dErr := c.Conn.SetReadDeadline(deadline); err == nil {
+				err = dErr
+			}
+		}()
+	}
+	_, err = io.ReadFull(c.r, salt)
+	return
+}
+
+func (c *EncryptedConn) aead(label string, serverSalt, clientSalt []byte) (
+	aead cipher.AEAD,
+) {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(label))
+	mac.Write(serverSalt)
+	mac.Write(clientSalt)
+	// A 32-byte key is always valid for AES-256, and AES always works with GCM.
+	block, _ := aes.NewCipher(mac.Sum(nil))
+	aead, _ = cipher.NewGCM(block)
+	return
+}
+
+// seal encrypts p and writes the resulting frame to the connection.
+func (c *EncryptedConn) seal(p []byte) (err error) {
+	c.sendCounter++
+	var counter [counterSize]byte
+	binary.BigEndian.PutUint64(counter[:], c.sendCounter)
+	c.sealed = c.send.Seal(c.sealed[:0], nonce(c.send, counter[:]), p, nil)
+	c.frame.Reset()
+	c.frame.Write(counter[:])
+	varint.PositiveInt.Marshal(len(c.sealed), &c.frame) // never fails
+	c.frame.Write(c.sealed)
+	_, err = c.Conn.Write(c.frame.Bytes())
+	return
+}
+
+// open reads a sealed frame and decrypts it to the plain buffer.
+func (c *EncryptedConn) open() (err error) {
+	var counter [counterSize]byte
+	if _, err = io.ReadFull(c.r, counter[:]); err != nil {
+		return
+	}
+	length, _, err := varint.PositiveInt.Unmarshal(c.r)
+	if err != nil {
+		return
+	}
+	if length < 0 || length > MaxSealedFrameSize {
+		return ErrSealedFrameTooLarge
+	}
+	if cap(c.ciphertext) < length {
+		c.ciphertext = make([]byte, length)
+	}
+	c.ciphertext = c.ciphertext[:length]
+	if _, err = io.ReadFull(c.r, c.ciphertext); err != nil {
+		return
+	}
+	cn := binary.BigEndian.Uint64(counter[:])
+	if cn <= c.recvCounter {
+		return ErrReplayedFrame
+	}
+	c.opened, err = c.recv.Open(c.opened[:0], nonce(c.recv, counter[:]),
+		c.ciphertext, nil)
+	if err != nil {
+		return ErrTamperedFrame
+	}
+	c.recvCounter = cn
+	c.plain = c.opened
+	return
+}
+
+func nonce(aead cipher.AEAD, counter []byte) []byte {
+	n := make([]byte, aead.NonceSize())
+	copy(n[len(n)-counterSize:], counter)
+	return n
+}
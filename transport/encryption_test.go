@@ -0,0 +1,230 @@
+package transport_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cmd-stream/delegate-go"
+	"github.com/cmd-stream/delegate-go/transport"
+	asserterror "github.com/ymz-ncnk/assert/error"
+)
+
+func TestEncryption(t *testing.T) {
+	var (
+		key        = []byte("pre-shared key")
+		serverInfo = delegate.ServerInfo("server info")
+		cmd        = BlobCmd("cmd")
+		result     = BlobResult("result")
+	)
+
+	t.Run("Peers with the same key should exchange values", func(t *testing.T) {
+		cln, srv := transport.NewPipe[any](BlobClientCodec{}, BlobServerCodec{},
+			transport.WithEncryption(key))
+		defer cln.Close()
+		defer srv.Close()
+		handshake(t, cln, srv, serverInfo)
+
+		exchange(t, cln, srv, cmd, result)
+		exchange(t, cln, srv, cmd, result)
+	})
+
+	t.Run("Encryption should work together with compression", func(t *testing.T) {
+		var (
+			c    = transport.CompressionFlate
+			info = delegate.VersionedInfo{
+				Service:  "service",
+				Features: []string{c.Feature()},
+			}.ServerInfo()
+			bigCmd = BlobCmd(strings.Repeat("cmd", 1000))
+			ops    = []transport.SetOption{transport.WithEncryption(key),
+				transport.WithCompression(c)}
+			cln, srv, _, srvConn = countingPipe(ops, ops)
+		)
+		defer cln.Close()
+		defer srv.Close()
+		handshake(t, cln, srv, info)
+		srvConn.count() // skip the salt
+
+		exchange(t, cln, srv, bigCmd, result)
+		if n := srvConn.count(); n >= len(bigCmd) {
+			t.Errorf("Cmd was not compressed, %v bytes received", n)
+		}
+	})
+
+	t.Run("If peers use different keys, the handshake should fail with ErrTamperedFrame",
+		func(t *testing.T) {
+			var (
+				cln, srv = pipe(
+					[]transport.SetOption{transport.WithEncryption(key)},
+					[]transport.SetOption{transport.WithEncryption([]byte("another key"))},
+				)
+				errs = make(chan error, 1)
+			)
+			defer cln.Close()
+			defer srv.Close()
+
+			go func() { errs <- srv.SendServerInfo(serverInfo) }()
+			_, err := cln.ReceiveServerInfo(0)
+			asserterror.EqualError(t, err, transport.ErrTamperedFrame)
+			asserterror.EqualError(t, <-errs, nil)
+		})
+
+	t.Run("Handshake messages sent after ServerInfo should be sealed",
+		func(t *testing.T) {
+			var (
+				info           = delegate.ClientInfo("client info")
+				cln, srv, conn = recordingPipe(transport.WithEncryption(key))
+			)
+			defer cln.Close()
+			defer srv.Close()
+			handshake(t, cln, srv, serverInfo)
+
+			conn.record = true
+			asserterror.EqualError(t, cln.SendClientInfo(info), nil)
+			if conn.buf.Len() == 0 || bytes.Contains(conn.buf.Bytes(), info) {
+				t.Errorf("ClientInfo was not sealed, %v bytes sent", conn.buf.Len())
+			}
+		})
+
+	t.Run("If the client does not send its salt, SendServerInfo should fail after KeyExchangeTimeout",
+		func(t *testing.T) {
+			var (
+				timeout = 100 * time.Millisecond
+				c1, c2  = net.Pipe()
+				srv     = transport.NewServer[any](c2, BlobServerCodec{},
+					transport.WithEncryption(key),
+					transport.WithKeyExchangeTimeout(timeout))
+				start = time.Now()
+			)
+			defer c1.Close()
+			defer srv.Close()
+			go io.ReadFull(c1, make([]byte, 32)) // reads the server's salt only
+
+			err := srv.SendServerInfo(serverInfo)
+			if !errors.Is(err, os.ErrDeadlineExceeded) {
+				t.Errorf("unexpected error %v", err)
+			}
+			asserterror.SameTime(t, time.Now(), start.Add(timeout),
+				50*time.Millisecond)
+		})
+
+	t.Run("EncryptedConn should work over any net.Conn", func(t *testing.T) {
+		var (
+			c1, c2 = net.Pipe()
+			cln    = transport.NewClientEncryptedConn(c1, key, time.Second)
+			srv    = transport.NewServerEncryptedConn(c2, key, time.Second)
+			data   = bytes.Repeat([]byte("data"), 1000)
+			errs   = make(chan error, 1)
+		)
+		defer cln.Close()
+		defer srv.Close()
+		go func() {
+			_, err := srv.Write(data)
+			errs <- err
+		}()
+		b := make([]byte, len(data))
+		_, err := io.ReadFull(cln, b)
+		asserterror.EqualError(t, err, nil)
+		asserterror.EqualDeep(t, b, data)
+		asserterror.EqualError(t, <-errs, nil)
+	})
+
+	t.Run("If a frame is replayed, Receive should return ErrReplayedFrame",
+		func(t *testing.T) {
+			cln, srv, conn := recordingPipe(transport.WithEncryption(key))
+			defer cln.Close()
+			defer srv.Close()
+			handshake(t, cln, srv, serverInfo)
+
+			frame := record(t, cln, conn)
+			go conn.Conn.Write(append(frame, frame...))
+			_, _, _, err := srv.Receive()
+			asserterror.EqualError(t, err, nil)
+			_, _, _, err = srv.Receive()
+			asserterror.EqualError(t, err, transport.ErrReplayedFrame)
+		})
+
+	t.Run("If a frame is tampered with, Receive should return ErrTamperedFrame",
+		func(t *testing.T) {
+			cln, srv, conn := recordingPipe(transport.WithEncryption(key))
+			defer cln.Close()
+			defer srv.Close()
+			handshake(t, cln, srv, serverInfo)
+
+			frame := record(t, cln, conn)
+			frame[len(frame)-1] ^= 1
+			go conn.Conn.Write(frame)
+			_, _, _, err := srv.Receive()
+			asserterror.EqualError(t, err, transport.ErrTamperedFrame)
+		})
+
+	for _, c := range []struct {
+		name   string
+		length []byte
+	}{
+		{"exceeds MaxSealedFrameSize",
+			binary.AppendUvarint(nil, transport.MaxSealedFrameSize+1)},
+		{"overflows int", binary.AppendUvarint(nil, 1<<63)},
+	} {
+		t.Run("If the frame length "+c.name+", Read should return ErrSealedFrameTooLarge",
+			func(t *testing.T) {
+				var (
+					c1, c2 = net.Pipe()
+					conn   = transport.NewServerEncryptedConn(c2, key, time.Second)
+				)
+				defer c1.Close()
+				defer conn.Close()
+				go func() {
+					io.ReadFull(c1, make([]byte, 32))
+					c1.Write(make([]byte, 32)) // salt
+					c1.Write(append(make([]byte, 8), c.length...))
+				}()
+				_, err := conn.Read(make([]byte, 1))
+				asserterror.EqualError(t, err, transport.ErrSealedFrameTooLarge)
+			})
+	}
+}
+
+func recordingPipe(ops ...transport.SetOption) (
+	cln transport.ClientTransport[any], srv transport.ServerTransport[any],
+	conn *recordingConn,
+) {
+	c1, c2 := net.Pipe()
+	conn = &recordingConn{Conn: c1}
+	cln = transport.NewClient[any](conn, BlobClientCodec{}, ops...)
+	srv = transport.NewServer[any](c2, BlobServerCodec{}, ops...)
+	return
+}
+
+// record returns the frame sent by the client instead of sending it.
+func record(t *testing.T, cln transport.ClientTransport[any],
+	conn *recordingConn,
+) []byte {
+	conn.record = true
+	_, err := cln.Send(1, BlobCmd("cmd"))
+	asserterror.EqualError(t, err, nil)
+	asserterror.EqualError(t, cln.Flush(), nil)
+	conn.record = false
+	defer conn.buf.Reset()
+	return bytes.Clone(conn.buf.Bytes())
+}
+
+type recordingConn struct {
+	net.Conn
+	record bool
+	buf    bytes.Buffer
+}
+
+func (c *recordingConn) Write(b []byte) (int, error) {
+	if c.record {
+		return c.buf.Write(b)
+	}
+	return c.Conn.Write(b)
+}
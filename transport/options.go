@@ -0,0 +1,95 @@
+package transport
+
+import "time"
+
+// DefaultBufSize is the default size of the Transport's read and write
+// buffers.
+const DefaultBufSize = 4096
+
+// DefaultMaxClientInfoSize is the default maximum size of ClientInfo received
+// by ServerTransport.
+const DefaultMaxClientInfoSize = 4096
+
+type Options struct {
+	WriterBufSize        int
+	ReaderBufSize        int
+	MaxClientInfoSize    int
+	Compression          Compression
+	CompressionThreshold int
+	EncryptionKey        []byte
+	KeyExchangeTimeout   time.Duration
+	Checksum             bool
+}
+
+type SetOption func(o *Options)
+
+// WithWriterBufSize sets the size of the write buffer. Defaults to
+// DefaultBufSize.
+func WithWriterBufSize(size int) SetOption {
+	return func(o *Options) { o.WriterBufSize = size }
+}
+
+// WithReaderBufSize sets the size of the read buffer. Defaults to
+// DefaultBufSize.
+func WithReaderBufSize(size int) SetOption {
+	return func(o *Options) { o.ReaderBufSize = size }
+}
+
+// WithMaxClientInfoSize makes ServerTransport decode ClientInfo with
+// delegate.NewBoundedClientInfoMUS, so a bigger ClientInfo causes
+// delegate.ErrClientInfoTooLarge before memory for it is allocated. Defaults
+// to DefaultMaxClientInfoSize. If set to 0, the size is not limited.
+func WithMaxClientInfoSize(size int) SetOption {
+	return func(o *Options) { o.MaxClientInfoSize = size }
+}
+
+// WithCompression makes NewClient and NewServer wrap the connection in
+// CompressedConn, so data is compressed with the specified algorithm.
+//
+// The server offers compression by including c.Feature() in the features of
+// its delegate.VersionedInfo, the client accepts it only if it also uses this
+// option with the same algorithm. This way, old peers keep exchanging
+// uncompressed data.
+func WithCompression(c Compression) SetOption {
+	return func(o *Options) { o.Compression = c }
+}
+
+// WithCompressionThreshold sets the size of a Write, below which it is sent
+// uncompressed. Defaults to DefaultCompressionThreshold.
+func WithCompressionThreshold(size int) SetOption {
+	return func(o *Options) { o.CompressionThreshold = size }
+}
+
+// WithEncryption makes NewClient and NewServer wrap the connection in
+// EncryptedConn, so all data, including the handshake, is sealed with AES-GCM
+// and received data that was replayed or tampered with is rejected.
+//
+// Session keys are derived from the pre-shared key and random salts, which
+// peers exchange before anything else, so both of them must use this option
+// with the same key.
+func WithEncryption(key []byte) SetOption {
+	return func(o *Options) { o.EncryptionKey = key }
+}
+
+// WithKeyExchangeTimeout sets how long EncryptedConn waits for the peer's
+// salt. Defaults to DefaultKeyExchangeTimeout. If == 0, it waits forever.
+func WithKeyExchangeTimeout(d time.Duration) SetOption {
+	return func(o *Options) { o.KeyExchangeTimeout = d }
+}
+
+// WithChecksum makes the Transport wrap the connection in ChecksumConn, so
+// all data is sent with CRC32C checksums, which are verified on receive. If
+// a checksum does not match, Receive closes the connection and returns
+// ErrChecksumMismatch, so that the client can reconnect. Both peers must use
+// this option.
+func WithChecksum() SetOption {
+	return func(o *Options) { o.Checksum = true }
+}
+
+func Apply(ops []SetOption, o *Options) {
+	for i := range ops {
+		if ops[i] != nil {
+			ops[i](o)
+		}
+	}
+}
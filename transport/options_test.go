@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestOptions(t *testing.T) {
+	var (
+		o                     = Options{}
+		wantWriterBufSize     = 1024
+		wantReaderBufSize     = 2048
+		wantMaxClientInfoSize = 256
+		wantCompression       = CompressionGzip
+		wantThreshold         = 256
+		wantEncryptionKey     = []byte("key")
+		wantKeyExchange       = time.Second
+	)
+	Apply([]SetOption{
+		WithWriterBufSize(wantWriterBufSize),
+		WithReaderBufSize(wantReaderBufSize),
+		WithMaxClientInfoSize(wantMaxClientInfoSize),
+		WithCompression(wantCompression),
+		WithCompressionThreshold(wantThreshold),
+		WithEncryption(wantEncryptionKey),
+		WithKeyExchangeTimeout(wantKeyExchange),
+		WithChecksum(),
+	}, &o)
+
+	if o.WriterBufSize != wantWriterBufSize {
+		t.Errorf("unexpected WriterBufSize, want %v actual %v",
+			wantWriterBufSize, o.WriterBufSize)
+	}
+
+	if o.ReaderBufSize != wantReaderBufSize {
+		t.Errorf("unexpected ReaderBufSize, want %v actual %v",
+			wantReaderBufSize, o.ReaderBufSize)
+	}
+
+	if o.MaxClientInfoSize != wantMaxClientInfoSize {
+		t.Errorf("unexpected MaxClientInfoSize, want %v actual %v",
+			wantMaxClientInfoSize, o.MaxClientInfoSize)
+	}
+
+	if o.Compression != wantCompression {
+		t.Errorf("unexpected Compression, want %v actual %v",
+			wantCompression, o.Compression)
+	}
+
+	if o.CompressionThreshold != wantThreshold {
+		t.Errorf("unexpected CompressionThreshold, want %v actual %v",
+			wantThreshold, o.CompressionThreshold)
+	}
+
+	if !bytes.Equal(o.EncryptionKey, wantEncryptionKey) {
+		t.Errorf("unexpected EncryptionKey, want %v actual %v",
+			wantEncryptionKey, o.EncryptionKey)
+	}
+
+	if o.KeyExchangeTimeout != wantKeyExchange {
+		t.Errorf("unexpected KeyExchangeTimeout, want %v actual %v",
+			wantKeyExchange, o.KeyExchangeTimeout)
+	}
+
+	if !o.Checksum {
+		t.Error("unexpected Checksum, want true actual false")
+	}
+}
@@ -0,0 +1,126 @@
+package transport
+
+import (
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cmd-stream/core-go"
+	dcln "github.com/cmd-stream/delegate-go/client"
+)
+
+// NewPipe creates a client and server Transport connected in memory with
+// net.Pipe, so deadlines and Close behave like on a real connection. As
+// net.Pipe is unbuffered, Flush blocks until the other side reads the data.
+func NewPipe[T any](clientCodec Codec[core.Cmd[T], core.Result],
+	serverCodec Codec[core.Result, core.Cmd[T]],
+	ops ...SetOption,
+) (ClientTransport[T], ServerTransport[T]) {
+	c1, c2 := net.Pipe()
+	return NewClient(c1, clientCodec, ops...), NewServer(c2, serverCodec, ops...)
+}
+
+// NewPipeListener creates a new PipeListener.
+func NewPipeListener() *PipeListener {
+	return &PipeListener{
+		conns:    make(chan net.Conn),
+		done:     make(chan struct{}),
+		deadline: make(chan struct{}),
+	}
+}
+
+// PipeListener is an in-memory core.Listener. Connections are established
+// with Dial, which makes it possible to run a server and clients, including
+// reconnects, without sockets.
+type PipeListener struct {
+	conns    chan net.Conn
+	done     chan struct{}
+	once     sync.Once
+	mu       sync.Mutex
+	timer    *time.Timer
+	deadline chan struct{}
+}
+
+func (l *PipeListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+// SetDeadline sets the deadline for Accept.
+func (l *PipeListener) SetDeadline(deadline time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	l.deadline = make(chan struct{})
+	if deadline.IsZero() {
+		return nil
+	}
+	ch := l.deadline
+	l.timer = time.AfterFunc(time.Until(deadline), func() { close(ch) })
+	return nil
+}
+
+func (l *PipeListener) Accept() (conn net.Conn, err error) {
+	l.mu.Lock()
+	deadline := l.deadline
+	l.mu.Unlock()
+	select {
+	case conn = <-l.conns:
+		return
+	case <-l.done:
+		return nil, net.ErrClosed
+	case <-deadline:
+		return nil, os.ErrDeadlineExceeded
+	}
+}
+
+// Dial connects to the listener. It blocks until the connection is accepted
+// or the listener is closed.
+func (l *PipeListener) Dial() (net.Conn, error) {
+	c1, c2 := net.Pipe()
+	select {
+	case l.conns <- c2:
+		return c1, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes the listener, already accepted connections stay open.
+func (l *PipeListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return nil
+}
+
+// NewPipeFactory creates a new PipeFactory.
+func NewPipeFactory[T any](l *PipeListener,
+	codec Codec[core.Cmd[T], core.Result],
+	ops ...SetOption,
+) PipeFactory[T] {
+	return PipeFactory[T]{l: l, codec: codec, ops: ops}
+}
+
+// PipeFactory implements the client.TransportFactory interface, it creates
+// Transports connected to PipeListener.
+type PipeFactory[T any] struct {
+	l     *PipeListener
+	codec Codec[core.Cmd[T], core.Result]
+	ops   []SetOption
+}
+
+func (f PipeFactory[T]) New() (dcln.Transport[T], error) {
+	conn, err := f.l.Dial()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn, f.codec, f.ops...), nil
+}
+
+type pipeAddr struct{}
+
+func (a pipeAddr) Network() string { return "pipe" }
+
+func (a pipeAddr) String() string { return "pipe" }
@@ -0,0 +1,103 @@
+package transport_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cmd-stream/core-go"
+	"github.com/cmd-stream/delegate-go"
+	dcln "github.com/cmd-stream/delegate-go/client"
+	dsrv "github.com/cmd-stream/delegate-go/server"
+	"github.com/cmd-stream/delegate-go/transport"
+	asserterror "github.com/ymz-ncnk/assert/error"
+)
+
+func TestPipe(t *testing.T) {
+	serverInfo := delegate.ServerInfo("server info")
+
+	t.Run("Client and server Transports should be connected", func(t *testing.T) {
+		var (
+			wantCmd  = Cmd(1)
+			cln, srv = transport.NewPipe[any](ClientCodec{}, ServerCodec{})
+			errs     = make(chan error, 1)
+		)
+		defer cln.Close()
+		defer srv.Close()
+		go func() { errs <- srv.SendServerInfo(serverInfo) }()
+		delegateCln, err := dcln.New(serverInfo, dcln.Transport[any](cln))
+		asserterror.EqualError(t, err, nil)
+		asserterror.EqualError(t, <-errs, nil)
+
+		go func() {
+			_, err := delegateCln.Send(1, wantCmd)
+			if err == nil {
+				err = delegateCln.Flush()
+			}
+			errs <- err
+		}()
+		seq, cmd, _, err := srv.Receive()
+		asserterror.EqualError(t, err, nil)
+		asserterror.Equal(t, seq, core.Seq(1))
+		asserterror.Equal[core.Cmd[any]](t, cmd, wantCmd)
+		asserterror.EqualError(t, <-errs, nil)
+	})
+
+	t.Run("ReconnectDelegate should reconnect to PipeListener",
+		func(t *testing.T) {
+			var (
+				l       = transport.NewPipeListener()
+				handler = dsrv.TransportHandlerFunc[any](
+					func(ctx context.Context, tran dsrv.Transport[any]) (err error) {
+						_, _, _, err = tran.Receive()
+						return
+					})
+				delegateSrv = dsrv.New(serverInfo,
+					transport.NewServerFactory[any](ServerCodec{}), handler)
+				factory = transport.NewPipeFactory[any](l, ClientCodec{})
+			)
+			defer l.Close()
+			go func() {
+				for {
+					conn, err := l.Accept()
+					if err != nil {
+						return
+					}
+					go delegateSrv.Handle(context.Background(), conn)
+				}
+			}()
+			delegateCln, err := dcln.NewReconnect(serverInfo, factory)
+			asserterror.EqualError(t, err, nil)
+			first := delegateCln.Transport()
+			asserterror.EqualError(t, first.Close(), nil)
+
+			err = delegateCln.Reconnect()
+			asserterror.EqualError(t, err, nil)
+			if delegateCln.Transport() == first {
+				t.Error("transport was not replaced")
+			}
+			asserterror.EqualError(t, delegateCln.Close(), nil)
+		})
+
+	t.Run("If the deadline expires, Accept should return os.ErrDeadlineExceeded",
+		func(t *testing.T) {
+			l := transport.NewPipeListener()
+			defer l.Close()
+			err := l.SetDeadline(time.Now().Add(10 * time.Millisecond))
+			asserterror.EqualError(t, err, nil)
+			_, err = l.Accept()
+			asserterror.EqualError(t, err, os.ErrDeadlineExceeded)
+		})
+
+	t.Run("If PipeListener is closed, Accept and Dial should return net.ErrClosed",
+		func(t *testing.T) {
+			l := transport.NewPipeListener()
+			asserterror.EqualError(t, l.Close(), nil)
+			_, err := l.Accept()
+			asserterror.EqualError(t, err, net.ErrClosed)
+			_, err = l.Dial()
+			asserterror.EqualError(t, err, net.ErrClosed)
+		})
+}
@@ -0,0 +1,81 @@
+package transport
+
+import (
+	"net"
+
+	"github.com/cmd-stream/core-go"
+	"github.com/cmd-stream/delegate-go"
+	dsrv "github.com/cmd-stream/delegate-go/server"
+	muss "github.com/mus-format/mus-stream-go"
+)
+
+// NewServer creates a new ServerTransport.
+func NewServer[T any](conn net.Conn, codec Codec[core.Result, core.Cmd[T]],
+	ops ...SetOption,
+) ServerTransport[T] {
+	return ServerTransport[T]{newTransport(conn, codec, ops,
+		NewServerEncryptedConn)}
+}
+
+// ServerTransport implements the server.Transport interface.
+type ServerTransport[T any] struct {
+	*Transport[core.Result, core.Cmd[T]]
+}
+
+func (t ServerTransport[T]) SendPreamble(p delegate.Preamble) error {
+	return send(t, t.w, delegate.PreambleMUS, p)
+}
+
+func (t ServerTransport[T]) SendAdmission(reason delegate.RejectReason) error {
+	return send(t, t.w, delegate.RejectReasonMUS, reason)
+}
+
+func (t ServerTransport[T]) SendRedirect(r delegate.Redirect) error {
+	return send(t, t.w, delegate.RedirectMUS, r)
+}
+
+func (t ServerTransport[T]) SendServerInfo(info delegate.ServerInfo) error {
+	if t.comp != nil {
+		t.comp.offer(info)
+	}
+	return send(t, t.w, delegate.ServerInfoMUS, info)
+}
+
+func (t ServerTransport[T]) SendAuthChallenge(c delegate.AuthChallenge) error {
+	return send(t, t.w, delegate.AuthChallengeMUS, c)
+}
+
+func (t ServerTransport[T]) ReceiveAuthResponse() (r delegate.AuthResponse,
+	err error,
+) {
+	r, _, err = delegate.AuthResponseMUS.Unmarshal(t.r)
+	return
+}
+
+func (t ServerTransport[T]) ReceiveClientInfo() (info delegate.ClientInfo,
+	err error,
+) {
+	var ser muss.Serializer[delegate.ClientInfo] = delegate.ClientInfoMUS
+	if t.options.MaxClientInfoSize != 0 {
+		ser = delegate.NewBoundedClientInfoMUS(t.options.MaxClientInfoSize)
+	}
+	info, _, err = ser.Unmarshal(t.r)
+	return
+}
+
+// NewServerFactory creates a new ServerFactory.
+func NewServerFactory[T any](codec Codec[core.Result, core.Cmd[T]],
+	ops ...SetOption,
+) ServerFactory[T] {
+	return ServerFactory[T]{codec: codec, ops: ops}
+}
+
+// ServerFactory implements the server.TransportFactory interface.
+type ServerFactory[T any] struct {
+	codec Codec[core.Result, core.Cmd[T]]
+	ops   []SetOption
+}
+
+func (f ServerFactory[T]) New(conn net.Conn) dsrv.Transport[T] {
+	return NewServer(conn, f.codec, f.ops...)
+}
@@ -0,0 +1,147 @@
+// Package transport provides a reference implementation of the delegate
+// transports over a net.Conn.
+//
+// Each value is sent as core.Seq followed by the value encoded with a Codec,
+// handshake messages are encoded with the MUS serializers of the delegate
+// package. Data is buffered and sent to the connection on Flush.
+//
+// CompressedConn, EncryptedConn and ChecksumConn are net.Conn decorators,
+// which can be used with any Transport built on a net.Conn. The Transports of
+// this package apply them with the WithCompression, WithEncryption and
+// WithChecksum options.
+//
+// Deprecated: migrate to github.com/cmd-stream/cmd-stream-go instead.
+package transport
+
+import (
+	"bufio"
+	"net"
+	"time"
+
+	"github.com/cmd-stream/core-go"
+	muss "github.com/mus-format/mus-stream-go"
+	"github.com/mus-format/mus-stream-go/varint"
+)
+
+// Codec encodes values of type T sent by the Transport and decodes values of
+// type V received by it.
+type Codec[T, V any] interface {
+	Encode(t T, w muss.Writer) (n int, err error)
+	Decode(r muss.Reader) (v V, n int, err error)
+}
+
+// New creates a new Transport.
+//
+// Compression and encryption depend on the side of the connection, so
+// WithCompression and WithEncryption are applied only by NewClient and
+// NewServer.
+func New[T, V any](conn net.Conn, codec Codec[T, V],
+	ops ...SetOption,
+) *Transport[T, V] {
+	return newTransport(conn, codec, ops, nil)
+}
+
+// newTransport creates a new Transport. If encrypt is nil, the side of the
+// connection is unknown, so compression and encryption are not applied.
+func newTransport[T, V any](conn net.Conn, codec Codec[T, V],
+	ops []SetOption,
+	encrypt func(conn net.Conn, key []byte,
+		timeout time.Duration) *EncryptedConn,
+) *Transport[T, V] {
+	o := Options{
+		WriterBufSize:        DefaultBufSize,
+		ReaderBufSize:        DefaultBufSize,
+		MaxClientInfoSize:    DefaultMaxClientInfoSize,
+		CompressionThreshold: DefaultCompressionThreshold,
+		KeyExchangeTimeout:   DefaultKeyExchangeTimeout,
+	}
+	Apply(ops, &o)
+	if o.Checksum {
+		conn = NewChecksumConn(conn)
+	}
+	var comp *CompressedConn
+	if encrypt != nil {
+		if len(o.EncryptionKey) != 0 {
+			conn = encrypt(conn, o.EncryptionKey, o.KeyExchangeTimeout)
+		}
+		if o.Compression != CompressionNone {
+			comp = NewCompressedConn(conn, o.Compression, o.CompressionThreshold)
+			conn = comp
+		}
+	}
+	return &Transport[T, V]{
+		conn:    conn,
+		w:       bufio.NewWriterSize(conn, o.WriterBufSize),
+		r:       bufio.NewReaderSize(conn, o.ReaderBufSize),
+		codec:   codec,
+		options: o,
+		comp:    comp,
+	}
+}
+
+// Transport implements the delegate.Transport interface.
+type Transport[T, V any] struct {
+	conn    net.Conn
+	w       *bufio.Writer
+	r       *bufio.Reader
+	codec   Codec[T, V]
+	options Options
+	comp    *CompressedConn
+}
+
+func (t *Transport[T, V]) LocalAddr() net.Addr {
+	return t.conn.LocalAddr()
+}
+
+func (t *Transport[T, V]) RemoteAddr() net.Addr {
+	return t.conn.RemoteAddr()
+}
+
+func (t *Transport[T, V]) SetSendDeadline(deadline time.Time) error {
+	return t.conn.SetWriteDeadline(deadline)
+}
+
+func (t *Transport[T, V]) Send(seq core.Seq, v T) (n int, err error) {
+	n, err = varint.Int64.Marshal(int64(seq), t.w)
+	if err != nil {
+		return
+	}
+	var n1 int
+	n1, err = t.codec.Encode(v, t.w)
+	n += n1
+	return
+}
+
+func (t *Transport[T, V]) Flush() error {
+	return t.w.Flush()
+}
+
+func (t *Transport[T, V]) SetReceiveDeadline(deadline time.Time) error {
+	return t.conn.SetReadDeadline(deadline)
+}
+
+func (t *Transport[T, V]) Receive() (seq core.Seq, v V, n int, err error) {
+	s, n, err := varint.Int64.Unmarshal(t.r)
+	if err != nil {
+		return
+	}
+	seq = core.Seq(s)
+	var n1 int
+	v, n1, err = t.codec.Decode(t.r)
+	n += n1
+	return
+}
+
+func (t *Transport[T, V]) Close() error {
+	return t.conn.Close()
+}
+
+// send marshals a handshake message with ser and flushes it.
+func send[M any](t interface{ Flush() error }, w muss.Writer,
+	ser muss.Serializer[M], m M,
+) (err error) {
+	if _, err = ser.Marshal(m, w); err != nil {
+		return
+	}
+	return t.Flush()
+}
@@ -0,0 +1,197 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cmd-stream/core-go"
+	"github.com/cmd-stream/delegate-go"
+	dcln "github.com/cmd-stream/delegate-go/client"
+	dsrv "github.com/cmd-stream/delegate-go/server"
+	"github.com/cmd-stream/delegate-go/transport"
+	muss "github.com/mus-format/mus-stream-go"
+	asserterror "github.com/ymz-ncnk/assert/error"
+)
+
+func TestTransport(t *testing.T) {
+	serverInfo := delegate.ServerInfo("server info")
+
+	t.Run("Client and server delegates should communicate over the transports",
+		func(t *testing.T) {
+			var (
+				wantCmd    = Cmd(1)
+				wantResult = Result(2)
+				handled    = make(chan error, 1)
+				handler    = dsrv.TransportHandlerFunc[any](
+					func(ctx context.Context, tran dsrv.Transport[any]) (err error) {
+						seq, cmd, _, err := tran.Receive()
+						if err != nil {
+							return
+						}
+						asserterror.Equal[core.Cmd[any]](t, cmd, wantCmd)
+						if _, err = tran.Send(seq, wantResult); err != nil {
+							return
+						}
+						return tran.Flush()
+					})
+				delegateSrv = dsrv.New(serverInfo,
+					transport.NewServerFactory[any](ServerCodec{}), handler,
+					dsrv.WithPreamble())
+				l = listen(t)
+			)
+			go func() {
+				conn, err := l.Accept()
+				if err != nil {
+					handled <- err
+					return
+				}
+				handled <- delegateSrv.Handle(context.Background(), conn)
+			}()
+			factory := transport.NewClientFactory[any]("tcp", l.Addr().String(),
+				ClientCodec{})
+			tran, err := factory.New()
+			asserterror.EqualError(t, err, nil)
+			delegateCln, err := dcln.New(serverInfo, tran, dcln.WithPreamble())
+			asserterror.EqualError(t, err, nil)
+
+			_, err = delegateCln.Send(1, wantCmd)
+			asserterror.EqualError(t, err, nil)
+			asserterror.EqualError(t, delegateCln.Flush(), nil)
+			seq, result, n, err := delegateCln.Receive()
+			asserterror.EqualError(t, err, nil)
+			asserterror.Equal(t, seq, core.Seq(1))
+			asserterror.Equal[core.Result](t, result, wantResult)
+			asserterror.Equal(t, n, 2)
+
+			asserterror.EqualError(t, <-handled, nil)
+			asserterror.EqualError(t, delegateCln.Close(), nil)
+		})
+
+	t.Run("If ServerInfo exceeds maxSize, ReceiveServerInfo should return ErrServerInfoTooLarge",
+		func(t *testing.T) {
+			var (
+				c1, c2 = net.Pipe()
+				srv    = transport.NewServer[any](c1, ServerCodec{})
+				cln    = transport.NewClient[any](c2, ClientCodec{})
+			)
+			defer c1.Close()
+			defer c2.Close()
+			go srv.SendServerInfo(serverInfo)
+			_, err := cln.ReceiveServerInfo(len(serverInfo) - 1)
+			asserterror.EqualError(t, err, delegate.ErrServerInfoTooLarge)
+		})
+
+	t.Run("If ClientInfo exceeds MaxClientInfoSize, ReceiveClientInfo should return ErrClientInfoTooLarge",
+		func(t *testing.T) {
+			var (
+				info   = delegate.ClientInfo("client info")
+				c1, c2 = net.Pipe()
+				srv    = transport.NewServer[any](c1, ServerCodec{},
+					transport.WithMaxClientInfoSize(len(info)-1))
+				cln = transport.NewClient[any](c2, ClientCodec{})
+			)
+			defer c1.Close()
+			defer c2.Close()
+			go cln.SendClientInfo(info)
+			_, err := srv.ReceiveClientInfo()
+			asserterror.EqualError(t, err, delegate.ErrClientInfoTooLarge)
+		})
+
+	t.Run("Handshake messages should be sent and received", func(t *testing.T) {
+		var (
+			c1, c2       = net.Pipe()
+			srv          = transport.NewServer[any](c1, ServerCodec{})
+			cln          = transport.NewClient[any](c2, ClientCodec{})
+			wantRedirect = delegate.Redirect{Addr: "127.0.0.1:9001"}
+			wantInfo     = delegate.ClientInfo("client info")
+			errs         = make(chan error, 1)
+		)
+		defer c1.Close()
+		defer c2.Close()
+		go func() {
+			errs <- errors.Join(
+				srv.SendAdmission(delegate.RejectRedirect),
+				srv.SendRedirect(wantRedirect),
+			)
+		}()
+		reason, err := cln.ReceiveAdmission()
+		asserterror.EqualError(t, err, nil)
+		asserterror.Equal(t, reason, delegate.RejectRedirect)
+		redirect, err := cln.ReceiveRedirect()
+		asserterror.EqualError(t, err, nil)
+		asserterror.Equal(t, redirect, wantRedirect)
+		asserterror.EqualError(t, <-errs, nil)
+
+		go func() { errs <- cln.SendClientInfo(wantInfo) }()
+		info, err := srv.ReceiveClientInfo()
+		asserterror.EqualError(t, err, nil)
+		asserterror.EqualDeep(t, info, wantInfo)
+		asserterror.EqualError(t, <-errs, nil)
+	})
+}
+
+func listen(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+type Cmd byte
+
+func (c Cmd) Exec(ctx context.Context, seq core.Seq, at time.Time,
+	receiver any, proxy core.Proxy,
+) error {
+	return nil
+}
+
+type Result byte
+
+func (r Result) LastOne() bool { return true }
+
+type ClientCodec struct{}
+
+func (c ClientCodec) Encode(cmd core.Cmd[any], w muss.Writer) (n int,
+	err error,
+) {
+	if err = w.WriteByte(byte(cmd.(Cmd))); err != nil {
+		return
+	}
+	return 1, nil
+}
+
+func (c ClientCodec) Decode(r muss.Reader) (result core.Result, n int,
+	err error,
+) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return
+	}
+	return Result(b), 1, nil
+}
+
+type ServerCodec struct{}
+
+func (c ServerCodec) Encode(result core.Result, w muss.Writer) (n int,
+	err error,
+) {
+	if err = w.WriteByte(byte(result.(Result))); err != nil {
+		return
+	}
+	return 1, nil
+}
+
+func (c ServerCodec) Decode(r muss.Reader) (cmd core.Cmd[any], n int,
+	err error,
+) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return
+	}
+	return Cmd(b), 1, nil
+}
@@ -0,0 +1,94 @@
+package delegate
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+)
+
+// Version is a semantic version of a service.
+type Version struct {
+	Major uint64
+	Minor uint64
+	Patch uint64
+}
+
+// Compare returns -1 if v is less than o, 0 if they are equal, and +1 if v
+// is greater than o.
+func (v Version) Compare(o Version) int {
+	switch {
+	case v.Major != o.Major:
+		return cmpUint64(v.Major, o.Major)
+	case v.Minor != o.Minor:
+		return cmpUint64(v.Minor, o.Minor)
+	default:
+		return cmpUint64(v.Patch, o.Patch)
+	}
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// VersionRange is a range of compatible versions, where Min is inclusive and
+// Max is exclusive. If Max is zero, the range has no upper bound.
+type VersionRange struct {
+	Min Version
+	Max Version
+}
+
+// Contains checks whether the version falls within the range.
+func (r VersionRange) Contains(v Version) bool {
+	if v.Compare(r.Min) < 0 {
+		return false
+	}
+	return r.Max == (Version{}) || v.Compare(r.Max) < 0
+}
+
+// VersionedInfo is a structured ServerInfo, which allows the client to accept
+// a range of server versions instead of the exact ServerInfo.
+type VersionedInfo struct {
+	Service  string
+	Version  Version
+	Features []string
+}
+
+// HasFeatures checks whether all of the specified features are supported.
+func (i VersionedInfo) HasFeatures(features []string) bool {
+	for _, feature := range features {
+		if !slices.Contains(i.Features, feature) {
+			return false
+		}
+	}
+	return true
+}
+
+// ServerInfo encodes VersionedInfo with VersionedInfoMUS.
+func (i VersionedInfo) ServerInfo() ServerInfo {
+	buf := bytes.NewBuffer(make([]byte, 0, VersionedInfoMUS.Size(i)))
+	VersionedInfoMUS.Marshal(i, buf) // bytes.Buffer never returns an error
+	return buf.Bytes()
+}
+
+// ParseVersionedInfo decodes VersionedInfo from ServerInfo.
+//
+// Trailing bytes are ignored, so new fields can be appended to the format
+// without breaking older clients. No length in ServerInfo can exceed its
+// size, so a bigger one causes ErrVersionedInfoTooLarge before memory for it
+// is allocated.
+func ParseVersionedInfo(info ServerInfo) (i VersionedInfo, err error) {
+	i, _, err = NewBoundedVersionedInfoMUS(len(info)).Unmarshal(
+		bytes.NewReader(info))
+	return
+}
+
+func cmpUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,75 @@
+package delegate
+
+import (
+	"testing"
+
+	asserterror "github.com/ymz-ncnk/assert/error"
+)
+
+func TestVersionedInfo(t *testing.T) {
+	t.Run("VersionedInfo should be encoded and decoded", func(t *testing.T) {
+		wantInfo := VersionedInfo{
+			Service:  "service",
+			Version:  Version{Major: 1, Minor: 2, Patch: 3},
+			Features: []string{"feature1", "feature2"},
+		}
+		info, err := ParseVersionedInfo(wantInfo.ServerInfo())
+		asserterror.EqualError(t, err, nil)
+		asserterror.EqualDeep(t, info, wantInfo)
+	})
+
+	t.Run("ParseVersionedInfo should ignore trailing bytes", func(t *testing.T) {
+		var (
+			wantInfo = VersionedInfo{Service: "service", Features: []string{"f"}}
+			info     = append(wantInfo.ServerInfo(), 1, 2, 3)
+		)
+		i, err := ParseVersionedInfo(info)
+		asserterror.EqualError(t, err, nil)
+		asserterror.EqualDeep(t, i, wantInfo)
+	})
+
+	t.Run("If ServerInfo is truncated, ParseVersionedInfo should fail",
+		func(t *testing.T) {
+			info := VersionedInfo{Service: "service"}.ServerInfo()
+			_, err := ParseVersionedInfo(info[:3])
+			if err == nil {
+				t.Error("expected error")
+			}
+		})
+
+	t.Run("If a length in ServerInfo is hostile, ParseVersionedInfo should return ErrVersionedInfoTooLarge",
+		func(t *testing.T) {
+			hostileLen := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+			for _, info := range []ServerInfo{
+				hostileLen,
+				append([]byte{1, 's', 0, 0, 0}, hostileLen...),
+				append([]byte{1, 's', 0, 0, 0, 1}, hostileLen...),
+			} {
+				_, err := ParseVersionedInfo(info)
+				asserterror.EqualError(t, err, ErrVersionedInfoTooLarge)
+			}
+		})
+
+	t.Run("HasFeatures should check all features", func(t *testing.T) {
+		info := VersionedInfo{Features: []string{"a", "b"}}
+		asserterror.Equal(t, info.HasFeatures(nil), true)
+		asserterror.Equal(t, info.HasFeatures([]string{"b", "a"}), true)
+		asserterror.Equal(t, info.HasFeatures([]string{"a", "c"}), false)
+	})
+}
+
+func TestVersionRange(t *testing.T) {
+	var (
+		r = VersionRange{
+			Min: Version{Major: 1, Minor: 2},
+			Max: Version{Major: 2},
+		}
+		unbounded = VersionRange{Min: Version{Major: 1}}
+	)
+	asserterror.Equal(t, r.Contains(Version{Major: 1, Minor: 1, Patch: 9}), false)
+	asserterror.Equal(t, r.Contains(Version{Major: 1, Minor: 2}), true)
+	asserterror.Equal(t, r.Contains(Version{Major: 1, Minor: 9, Patch: 9}), true)
+	asserterror.Equal(t, r.Contains(Version{Major: 2}), false)
+	asserterror.Equal(t, unbounded.Contains(Version{Major: 100}), true)
+	asserterror.Equal(t, unbounded.Contains(Version{}), false)
+}